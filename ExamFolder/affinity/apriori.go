@@ -0,0 +1,315 @@
+package affinity
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"ExamFolder/catalog"
+	"ExamFolder/store"
+)
+
+// Level is what counts as an item in a transaction.
+type Level string
+
+const (
+	ByProduct  Level = "product"
+	ByCategory Level = "category"
+)
+
+func ParseLevel(s string) (Level, error) {
+	switch l := Level(s); l {
+	case ByProduct, ByCategory:
+		return l, nil
+	default:
+		return "", fmt.Errorf("affinity: unknown level %q (want product or category)", s)
+	}
+}
+
+// Options tune the mining. Support and confidence are fractions from 0 to 1.
+// MaxSize caps the itemset size; 0 means no cap.
+type Options struct {
+	Level         Level
+	MinSupport    float64
+	MinConfidence float64
+	MaxSize       int
+}
+
+// DefaultOptions mines products bought together in at least 5% of baskets
+// and keeps rules that hold at least half of the time.
+func DefaultOptions() Options {
+	return Options{Level: ByProduct, MinSupport: 0.05, MinConfidence: 0.5, MaxSize: 3}
+}
+
+// Validate reports options outside their range.
+func (o Options) Validate() error {
+	if o.Level != ByProduct && o.Level != ByCategory {
+		return fmt.Errorf("affinity: unknown level %q", o.Level)
+	}
+	if o.MinSupport <= 0 || o.MinSupport > 1 {
+		return fmt.Errorf("affinity: minimum support %v must be above 0 and at most 1", o.MinSupport)
+	}
+	if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return fmt.Errorf("affinity: minimum confidence %v must be between 0 and 1", o.MinConfidence)
+	}
+	if o.MaxSize < 0 {
+		return fmt.Errorf("affinity: max size %d must not be negative", o.MaxSize)
+	}
+	return nil
+}
+
+// Transactions turns every order into the sorted set of its product IDs or
+// categories. Empty orders are left out.
+func Transactions(customers []store.Customer, level Level) [][]string {
+	var transactions [][]string
+	for _, customer := range customers {
+		for _, order := range customer.History() {
+			var items []string
+			for _, product := range order.Products {
+				item := product.ID
+				if level == ByCategory {
+					item = product.Category
+				}
+				items = append(items, item)
+			}
+			if len(items) == 0 {
+				continue
+			}
+			slices.Sort(items)
+			transactions = append(transactions, slices.Compact(items))
+		}
+	}
+	return transactions
+}
+
+// Itemset is a set of items with the number and share of transactions that
+// contain all of them. Names are the product names for product items.
+type Itemset struct {
+	Items   []string `json:"items"`
+	Names   []string `json:"names"`
+	Count   int      `json:"count"`
+	Support float64  `json:"support"`
+}
+
+// Rule reads "baskets with Antecedent also hold Consequent".
+type Rule struct {
+	Antecedent Itemset `json:"antecedent"`
+	Consequent Itemset `json:"consequent"`
+	Count      int     `json:"count"`
+	Support    float64 `json:"support"`
+	Confidence float64 `json:"confidence"`
+	Lift       float64 `json:"lift"`
+}
+
+// Result is the outcome of Mine.
+type Result struct {
+	Options      Options   `json:"options"`
+	Transactions int       `json:"transactions"`
+	Itemsets     []Itemset `json:"itemsets"`
+	Rules        []Rule    `json:"rules"`
+}
+
+func key(items []string) string {
+	return strings.Join(items, "\x00")
+}
+
+// Mine runs Apriori over the customers' orders. Itemsets are ordered by size,
+// then by count descending, then by items; rules by lift, confidence and
+// support descending, then by their items.
+func Mine(customers []store.Customer, options Options) (Result, error) {
+	if err := options.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	transactions := Transactions(customers, options.Level)
+	result := Result{Options: options, Transactions: len(transactions)}
+	if len(transactions) == 0 {
+		return result, nil
+	}
+
+	minCount := int(math.Ceil(options.MinSupport*float64(len(transactions)) - 1e-9))
+	counts := make(map[string]int)
+
+	single := make(map[string]int)
+	for _, transaction := range transactions {
+		for _, item := range transaction {
+			single[item]++
+		}
+	}
+	var frequent [][]string
+	for item, count := range single {
+		if count >= minCount {
+			frequent = append(frequent, []string{item})
+			counts[item] = count
+		}
+	}
+	sortItemsets(frequent)
+
+	var all [][]string
+	for size := 1; len(frequent) > 0; size++ {
+		all = append(all, frequent...)
+		if options.MaxSize > 0 && size >= options.MaxSize {
+			break
+		}
+
+		candidates := candidates(frequent, counts)
+		frequent = nil
+		for _, candidate := range candidates {
+			count := 0
+			for _, transaction := range transactions {
+				if containsAll(transaction, candidate) {
+					count++
+				}
+			}
+			if count >= minCount {
+				counts[key(candidate)] = count
+				frequent = append(frequent, candidate)
+			}
+		}
+	}
+
+	c := catalog.Build(customers)
+	itemset := func(items []string) Itemset {
+		count := counts[key(items)]
+		set := Itemset{Items: items, Count: count, Support: float64(count) / float64(len(transactions))}
+		for _, item := range items {
+			name := item
+			if options.Level == ByProduct {
+				if entry, ok := c.Lookup(item); ok {
+					name = entry.Name
+				}
+			}
+			set.Names = append(set.Names, name)
+		}
+		return set
+	}
+
+	for _, items := range all {
+		result.Itemsets = append(result.Itemsets, itemset(items))
+	}
+	sort.SliceStable(result.Itemsets, func(i, j int) bool {
+		a, b := result.Itemsets[i], result.Itemsets[j]
+		if len(a.Items) != len(b.Items) {
+			return len(a.Items) < len(b.Items)
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return key(a.Items) < key(b.Items)
+	})
+
+	for _, items := range all {
+		if len(items) < 2 {
+			continue
+		}
+		whole := itemset(items)
+		for _, antecedent := range subsets(items) {
+			consequent := without(items, antecedent)
+			left, right := itemset(antecedent), itemset(consequent)
+
+			confidence := float64(whole.Count) / float64(left.Count)
+			if confidence < options.MinConfidence {
+				continue
+			}
+			result.Rules = append(result.Rules, Rule{
+				Antecedent: left,
+				Consequent: right,
+				Count:      whole.Count,
+				Support:    whole.Support,
+				Confidence: confidence,
+				Lift:       confidence / right.Support,
+			})
+		}
+	}
+	sort.SliceStable(result.Rules, func(i, j int) bool {
+		a, b := result.Rules[i], result.Rules[j]
+		switch {
+		case a.Lift != b.Lift:
+			return a.Lift > b.Lift
+		case a.Confidence != b.Confidence:
+			return a.Confidence > b.Confidence
+		case a.Support != b.Support:
+			return a.Support > b.Support
+		case key(a.Antecedent.Items) != key(b.Antecedent.Items):
+			return key(a.Antecedent.Items) < key(b.Antecedent.Items)
+		default:
+			return key(a.Consequent.Items) < key(b.Consequent.Items)
+		}
+	})
+
+	return result, nil
+}
+
+func sortItemsets(itemsets [][]string) {
+	sort.Slice(itemsets, func(i, j int) bool {
+		return slices.Compare(itemsets[i], itemsets[j]) < 0
+	})
+}
+
+// candidates joins sorted frequent itemsets of size k that share their first
+// k-1 items, and drops any whose k-subsets are not all frequent.
+func candidates(frequent [][]string, counts map[string]int) [][]string {
+	var result [][]string
+	for i := range frequent {
+		for j := i + 1; j < len(frequent); j++ {
+			a, b := frequent[i], frequent[j]
+			k := len(a)
+			if !slices.Equal(a[:k-1], b[:k-1]) {
+				break
+			}
+
+			candidate := append(append([]string{}, a...), b[k-1])
+			pruned := false
+			for drop := range candidate {
+				subset := append(append([]string{}, candidate[:drop]...), candidate[drop+1:]...)
+				if _, ok := counts[key(subset)]; !ok {
+					pruned = true
+					break
+				}
+			}
+			if !pruned {
+				result = append(result, candidate)
+			}
+		}
+	}
+	return result
+}
+
+// containsAll reports whether the sorted transaction holds every item of the
+// sorted itemset.
+func containsAll(transaction, items []string) bool {
+	i := 0
+	for _, item := range transaction {
+		if i < len(items) && item == items[i] {
+			i++
+		}
+	}
+	return i == len(items)
+}
+
+// subsets returns the non-empty proper subsets of items, keeping order.
+func subsets(items []string) [][]string {
+	var result [][]string
+	for mask := 1; mask < 1<<len(items)-1; mask++ {
+		var subset []string
+		for i, item := range items {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, item)
+			}
+		}
+		result = append(result, subset)
+	}
+	return result
+}
+
+func without(items, remove []string) []string {
+	var result []string
+	for _, item := range items {
+		if !slices.Contains(remove, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+package affinity
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+var categories = map[string]string{"A": "Snack", "B": "Snack", "C": "Drink", "D": "Bakery"}
+
+// testCustomers holds six orders over products A to D:
+//
+//	ABC, AB, AC, BC, ABCD, D
+//
+// plus an empty order and a repeated line, which must not count.
+func testCustomers() []store.Customer {
+	order := func(id string, items ...string) store.Basket {
+		b := store.Basket{ID: id}
+		for _, item := range items {
+			b.Products = append(b.Products, store.Product{ID: item, Category: categories[item], Name: "Name " + item, Quantity: 1})
+		}
+		return b
+	}
+	customers := []store.Customer{
+		{ID: "C1", Orders: []store.Basket{order("B1", "C", "A", "B", "A"), order("B2", "A", "B")}},
+		{ID: "C2", Orders: []store.Basket{order("B3", "A", "C"), order("B4", "B", "C"), order("B5")}},
+		{ID: "C3", Orders: []store.Basket{order("B6", "A", "B", "C", "D"), order("B7", "D")}},
+	}
+	for i := range customers {
+		customers[i].Basket = store.Consolidate(customers[i].Orders)
+	}
+	return customers
+}
+
+func TestTransactions(t *testing.T) {
+	want := [][]string{{"A", "B", "C"}, {"A", "B"}, {"A", "C"}, {"B", "C"}, {"A", "B", "C", "D"}, {"D"}}
+	if got := Transactions(testCustomers(), ByProduct); !reflect.DeepEqual(got, want) {
+		t.Errorf("Transactions = %v, want %v", got, want)
+	}
+}
+
+func TestMine(t *testing.T) {
+	// Support 0.3 of 6 orders needs 2. A, B and C are in 4 orders, D in 2;
+	// AB, AC and BC in 3; AD, BD and CD in only 1; ABC in 2.
+	result, err := Mine(testCustomers(), Options{Level: ByProduct, MinSupport: 0.3, MinConfidence: 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Transactions != 6 {
+		t.Errorf("Transactions = %d, want 6", result.Transactions)
+	}
+
+	var itemsets []string
+	for _, set := range result.Itemsets {
+		itemsets = append(itemsets, strings.Join(set.Items, "")+":"+string(rune('0'+set.Count)))
+	}
+	if want := []string{"A:4", "B:4", "C:4", "D:2", "AB:3", "AC:3", "BC:3", "ABC:2"}; !reflect.DeepEqual(itemsets, want) {
+		t.Errorf("itemsets = %v, want %v", itemsets, want)
+	}
+	if names := result.Itemsets[4].Names; !reflect.DeepEqual(names, []string{"Name A", "Name B"}) {
+		t.Errorf("AB names = %v", names)
+	}
+
+	// Pair rules: confidence 3/4, lift 0.75 / (4/6) = 1.125, support 3/6.
+	// AB -> C: confidence 2/3, lift (2/3) / (4/6) = 1, support 2/6.
+	// A -> BC and the like have confidence 2/4 and are dropped.
+	type rule struct {
+		from, to                  string
+		support, confidence, lift float64
+	}
+	want := []rule{
+		{"A", "B", 0.5, 0.75, 1.125},
+		{"A", "C", 0.5, 0.75, 1.125},
+		{"B", "A", 0.5, 0.75, 1.125},
+		{"B", "C", 0.5, 0.75, 1.125},
+		{"C", "A", 0.5, 0.75, 1.125},
+		{"C", "B", 0.5, 0.75, 1.125},
+		{"AB", "C", 1.0 / 3, 2.0 / 3, 1},
+		{"AC", "B", 1.0 / 3, 2.0 / 3, 1},
+		{"BC", "A", 1.0 / 3, 2.0 / 3, 1},
+	}
+	if len(result.Rules) != len(want) {
+		t.Fatalf("got %d rules, want %d: %+v", len(result.Rules), len(want), result.Rules)
+	}
+	for i, w := range want {
+		r := result.Rules[i]
+		got := rule{strings.Join(r.Antecedent.Items, ""), strings.Join(r.Consequent.Items, ""), r.Support, r.Confidence, r.Lift}
+		if got.from != w.from || got.to != w.to ||
+			math.Abs(got.support-w.support) > 1e-9 || math.Abs(got.confidence-w.confidence) > 1e-9 || math.Abs(got.lift-w.lift) > 1e-9 {
+			t.Errorf("rule %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestMineMaxSizeAndSupport(t *testing.T) {
+	result, err := Mine(testCustomers(), Options{Level: ByProduct, MinSupport: 0.3, MaxSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := result.Itemsets[len(result.Itemsets)-1]; len(last.Items) != 2 {
+		t.Errorf("MaxSize 2 still produced %v", last.Items)
+	}
+
+	// Support 0.5 needs 3 of 6: D and ABC drop out.
+	result, err = Mine(testCustomers(), Options{Level: ByProduct, MinSupport: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Itemsets) != 6 {
+		t.Errorf("got %d itemsets at support 0.5, want A, B, C, AB, AC, BC", len(result.Itemsets))
+	}
+
+	if _, err := Mine(testCustomers(), Options{Level: ByProduct, MinSupport: 0}); err == nil {
+		t.Error("Mine accepted a minimum support of 0")
+	}
+	if result, err := Mine(nil, DefaultOptions()); err != nil || result.Transactions != 0 || result.Itemsets != nil {
+		t.Errorf("Mine(nil) = %+v, %v", result, err)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	frequent := [][]string{{"A", "B"}, {"A", "C"}, {"A", "D"}, {"B", "C"}}
+	counts := map[string]int{key([]string{"A", "B"}): 3, key([]string{"A", "C"}): 3, key([]string{"A", "D"}): 2, key([]string{"B", "C"}): 3}
+
+	// ABC joins AB and AC and all its pairs are frequent. ABD and ACD are
+	// pruned because BD and CD are not; BC shares no prefix with the others.
+	if got, want := candidates(frequent, counts), [][]string{{"A", "B", "C"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+
+	singles := [][]string{{"A"}, {"B"}, {"C"}}
+	singleCounts := map[string]int{"A": 4, "B": 4, "C": 4}
+	if got, want := candidates(singles, singleCounts), [][]string{{"A", "B"}, {"A", "C"}, {"B", "C"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("candidates of singles = %v, want %v", got, want)
+	}
+}
+
+func TestCategoryMatrix(t *testing.T) {
+	m := CategoryMatrix(testCustomers())
+	if want := []string{"Bakery", "Drink", "Snack"}; !reflect.DeepEqual(m.Categories, want) {
+		t.Fatalf("categories = %v, want %v", m.Categories, want)
+	}
+	want := [][]int{
+		{2, 1, 1},
+		{1, 4, 4},
+		{1, 4, 5},
+	}
+	if !reflect.DeepEqual(m.Counts, want) {
+		t.Errorf("counts = %v, want %v", m.Counts, want)
+	}
+	if m.Count("Drink", "Snack") != 4 || m.Count("Toys", "Snack") != 0 {
+		t.Error("Count looked up the wrong cell")
+	}
+}
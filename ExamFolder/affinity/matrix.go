@@ -0,0 +1,56 @@
+package affinity
+
+import (
+	"slices"
+
+	"ExamFolder/store"
+)
+
+// Matrix counts baskets holding both categories of each pair. The diagonal
+// is the number of baskets holding that category at all.
+type Matrix struct {
+	Categories []string `json:"categories"`
+	Counts     [][]int  `json:"counts"`
+}
+
+// Count returns the cell of two categories, 0 if either is unknown.
+func (m Matrix) Count(a, b string) int {
+	i, ok := slices.BinarySearch(m.Categories, a)
+	if !ok {
+		return 0
+	}
+	j, ok := slices.BinarySearch(m.Categories, b)
+	if !ok {
+		return 0
+	}
+	return m.Counts[i][j]
+}
+
+// CategoryMatrix builds the co-occurrence matrix over every order, with
+// categories sorted by name.
+func CategoryMatrix(customers []store.Customer) Matrix {
+	transactions := Transactions(customers, ByCategory)
+
+	var categories []string
+	for _, transaction := range transactions {
+		categories = append(categories, transaction...)
+	}
+	slices.Sort(categories)
+	categories = slices.Compact(categories)
+
+	m := Matrix{Categories: categories, Counts: make([][]int, len(categories))}
+	for i := range m.Counts {
+		m.Counts[i] = make([]int, len(categories))
+	}
+
+	for _, transaction := range transactions {
+		for _, a := range transaction {
+			i, _ := slices.BinarySearch(categories, a)
+			for _, b := range transaction {
+				j, _ := slices.BinarySearch(categories, b)
+				m.Counts[i][j]++
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,79 @@
+package catalog
+
+import (
+	"ExamFolder/store"
+)
+
+// ProductQuantity is the number of units sold of one product ID.
+type ProductQuantity struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Units int    `json:"units"`
+}
+
+// SoldQuantity is the units sold per product ID and overall.
+type SoldQuantity struct {
+	Products []ProductQuantity `json:"products"`
+	Total    int               `json:"total"`
+}
+
+// ProductCount is how many basket lines a product ID appears on.
+type ProductCount struct {
+	Product store.Product `json:"product"`
+	Count   int           `json:"count"`
+}
+
+// TotalSoldQuantity sums units per product ID, so two products sharing a
+// name are counted apart. Names come from the catalog and products are in
+// first-appearance order.
+func (c *Catalog) TotalSoldQuantity(products []store.Product) SoldQuantity {
+	var result SoldQuantity
+	index := make(map[string]int)
+
+	for _, product := range products {
+		i, ok := index[product.ID]
+		if !ok {
+			i = len(result.Products)
+			index[product.ID] = i
+			result.Products = append(result.Products, ProductQuantity{ID: product.ID, Name: c.Product(product.ID, 0).Name})
+		}
+
+		result.Products[i].Units += product.Quantity
+		result.Total += product.Quantity
+	}
+
+	return result
+}
+
+// MostSoldProduct finds the product ID on the most basket lines and returns
+// its canonical catalog definition. Ties go to the lowest ID, as with
+// task.TopN, so the answer does not depend on input order; IDs are unique
+// here, so TopN's name tie-break never applies.
+func (c *Catalog) MostSoldProduct(products []store.Product) (ProductCount, bool) {
+	if len(products) == 0 {
+		return ProductCount{}, false
+	}
+
+	lines := make(map[string]int)
+	for _, product := range products {
+		lines[product.ID]++
+	}
+
+	var id string
+	best := 0
+	for candidate, count := range lines {
+		if count > best || count == best && candidate < id {
+			id, best = candidate, count
+		}
+	}
+
+	// Quantity is taken from the product's first line.
+	quantity := 0
+	for _, product := range products {
+		if product.ID == id {
+			quantity = product.Quantity
+			break
+		}
+	}
+	return ProductCount{Product: c.Product(id, quantity), Count: best}, true
+}
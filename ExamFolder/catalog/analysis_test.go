@@ -0,0 +1,49 @@
+package catalog
+
+import (
+	"slices"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func TestMostSoldProductTies(t *testing.T) {
+	lines := []store.Product{
+		{ID: "P3", Name: "Cake", Quantity: 1},
+		{ID: "P2", Name: "Bread", Quantity: 4},
+		{ID: "P3", Name: "Cake", Quantity: 2},
+		{ID: "P2", Name: "Bread", Quantity: 5},
+		{ID: "P1", Name: "Apple", Quantity: 9},
+	}
+
+	// P2 and P3 are both on two lines; the lower ID wins in any order.
+	for _, order := range [][]store.Product{lines, reversed(lines)} {
+		got, ok := FromProducts(order).MostSoldProduct(order)
+		if !ok || got.Product.ID != "P2" || got.Count != 2 || got.Product.Name != "Bread" {
+			t.Errorf("MostSoldProduct = %+v, %v; want P2 on 2 lines", got, ok)
+		}
+	}
+
+	if _, ok := FromProducts(nil).MostSoldProduct(nil); ok {
+		t.Error("MostSoldProduct of no lines reported a product")
+	}
+}
+
+func TestTotalSoldQuantity(t *testing.T) {
+	lines := []store.Product{
+		{ID: "P1", Name: "Milk", Quantity: 2},
+		{ID: "P2", Name: "Milk", Quantity: 1},
+		{ID: "P1", Name: "Milk", Quantity: 3},
+	}
+	got := FromProducts(lines).TotalSoldQuantity(lines)
+	want := []ProductQuantity{{"P1", "Milk", 5}, {"P2", "Milk", 1}}
+	if got.Total != 6 || !slices.Equal(got.Products, want) {
+		t.Errorf("TotalSoldQuantity = %+v, want %+v totalling 6", got, want)
+	}
+}
+
+func reversed(lines []store.Product) []store.Product {
+	r := slices.Clone(lines)
+	slices.Reverse(r)
+	return r
+}
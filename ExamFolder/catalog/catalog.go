@@ -0,0 +1,158 @@
+package catalog
+
+import (
+	"sort"
+
+	"ExamFolder/store"
+)
+
+// Entry is the canonical definition of one product ID. When baskets
+// disagree, each field takes the value seen on the most lines, and the
+// first one seen on a tie.
+type Entry struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Category string      `json:"category"`
+	Price    store.Money `json:"price"`
+	Lines    int         `json:"lines"`
+	Units    int         `json:"units"`
+}
+
+// Variant is one value a field took for a product ID and on how many lines.
+type Variant struct {
+	Value string `json:"value"`
+	Lines int    `json:"lines"`
+}
+
+// Conflict reports a product ID whose field differs between basket lines.
+type Conflict struct {
+	ProductID string    `json:"product_id"`
+	Field     string    `json:"field"`
+	Variants  []Variant `json:"variants"`
+}
+
+// Catalog is the product catalog extracted from every basket line.
+type Catalog struct {
+	Entries   []Entry    `json:"entries"`
+	Conflicts []Conflict `json:"conflicts"`
+
+	index map[string]int
+}
+
+// observed collects the values of one product ID while building.
+type observed struct {
+	id         string
+	lines      int
+	units      int
+	names      []Variant
+	categories []Variant
+	prices     []Variant
+	priceOf    map[string]store.Money
+}
+
+func count(variants []Variant, value string) []Variant {
+	for i := range variants {
+		if variants[i].Value == value {
+			variants[i].Lines++
+			return variants
+		}
+	}
+	return append(variants, Variant{Value: value, Lines: 1})
+}
+
+func canonical(variants []Variant) string {
+	best := variants[0]
+	for _, variant := range variants[1:] {
+		if variant.Lines > best.Lines {
+			best = variant
+		}
+	}
+	return best.Value
+}
+
+// Build extracts the catalog from all baskets. Entries are ordered by ID;
+// conflicts by ID, then field.
+func Build(customers []store.Customer) *Catalog {
+	var lines []store.Product
+	for _, customer := range customers {
+		lines = append(lines, customer.Basket.Products...)
+	}
+	return FromProducts(lines)
+}
+
+// FromProducts extracts the catalog from basket lines, as Build does.
+func FromProducts(lines []store.Product) *Catalog {
+	var products []*observed
+	byID := make(map[string]*observed)
+
+	for _, product := range lines {
+		o, ok := byID[product.ID]
+		if !ok {
+			o = &observed{id: product.ID, priceOf: make(map[string]store.Money)}
+			byID[product.ID] = o
+			products = append(products, o)
+		}
+
+		price := product.Price.String()
+		o.lines++
+		o.units += product.Quantity
+		o.names = count(o.names, product.Name)
+		o.categories = count(o.categories, product.Category)
+		o.prices = count(o.prices, price)
+		o.priceOf[price] = product.Price
+	}
+
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].id < products[j].id
+	})
+
+	c := &Catalog{index: make(map[string]int)}
+	for _, o := range products {
+		c.index[o.id] = len(c.Entries)
+		c.Entries = append(c.Entries, Entry{
+			ID:       o.id,
+			Name:     canonical(o.names),
+			Category: canonical(o.categories),
+			Price:    o.priceOf[canonical(o.prices)],
+			Lines:    o.lines,
+			Units:    o.units,
+		})
+
+		for _, field := range []struct {
+			name     string
+			variants []Variant
+		}{
+			{"category", o.categories},
+			{"name", o.names},
+			{"price", o.prices},
+		} {
+			if len(field.variants) > 1 {
+				c.Conflicts = append(c.Conflicts, Conflict{ProductID: o.id, Field: field.name, Variants: field.variants})
+			}
+		}
+	}
+
+	return c
+}
+
+// Lookup returns the canonical entry of a product ID.
+func (c *Catalog) Lookup(id string) (Entry, bool) {
+	i, ok := c.index[id]
+	if !ok {
+		return Entry{}, false
+	}
+	return c.Entries[i], true
+}
+
+// Product returns the canonical definition of id as a store.Product
+// carrying the given quantity.
+func (c *Catalog) Product(id string, quantity int) store.Product {
+	entry, _ := c.Lookup(id)
+	return store.Product{
+		ID:       id,
+		Category: entry.Category,
+		Name:     entry.Name,
+		Price:    entry.Price,
+		Quantity: quantity,
+	}
+}
@@ -0,0 +1,110 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"ExamFolder/store"
+)
+
+var (
+	ErrEmptyBasket       = errors.New("checkout: basket is empty")
+	ErrInvalidLine       = errors.New("checkout: invalid basket line")
+	ErrPriceMismatch     = errors.New("checkout: basket total does not match its products")
+	ErrInsufficientFunds = errors.New("checkout: insufficient funds")
+)
+
+// Order records a completed checkout.
+type Order struct {
+	ID         string          `json:"id"`
+	CustomerID string          `json:"customer_id"`
+	BasketID   string          `json:"basket_id"`
+	Lines      []store.Product `json:"lines"`
+	Total      store.Money     `json:"total"`
+	CashBefore store.Money     `json:"cash_before"`
+	CashAfter  store.Money     `json:"cash_after"`
+	PlacedAt   time.Time       `json:"placed_at"`
+}
+
+// Receipt renders the order as a printable receipt.
+func (o Order) Receipt() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Order %s (basket %s, customer %s)\n", o.ID, o.BasketID, o.CustomerID)
+	fmt.Fprintf(&b, "%s\n", o.PlacedAt.Format(time.RFC3339))
+	fmt.Fprintln(&b, "------------------------------")
+	for _, line := range o.Lines {
+		fmt.Fprintf(&b, "%-20s %3d x %12s = %12s\n", line.Name, line.Quantity, line.Price, line.LineTotal())
+	}
+	fmt.Fprintln(&b, "------------------------------")
+	fmt.Fprintf(&b, "Total:      %s\n", o.Total)
+	fmt.Fprintf(&b, "Cash:       %s\n", o.CashBefore)
+	fmt.Fprintf(&b, "Remaining:  %s\n", o.CashAfter)
+	return b.String()
+}
+
+// Register checks out baskets one at a time. All checkouts against the same
+// customers must go through one Register so debits never interleave.
+type Register struct {
+	mu   sync.Mutex
+	next int
+
+	// Now stamps orders; it defaults to time.Now.
+	Now func() time.Time
+}
+
+func NewRegister() *Register {
+	return &Register{Now: time.Now}
+}
+
+// Checkout recomputes the basket total from its products, checks it against
+// basket.Total and the customer's cash, and debits the customer. On error
+// the customer is left untouched.
+func (r *Register) Checkout(customer *store.Customer, basket store.Basket) (Order, error) {
+	if len(basket.Products) == 0 {
+		return Order{}, fmt.Errorf("%w: basket %s", ErrEmptyBasket, basket.ID)
+	}
+
+	for i, product := range basket.Products {
+		if product.Quantity <= 0 || product.Price.IsNegative() {
+			return Order{}, fmt.Errorf("%w: basket %s line %d (%s) has quantity %d and price %s",
+				ErrInvalidLine, basket.ID, i+1, product.ID, product.Quantity, product.Price)
+		}
+	}
+
+	total := basket.ProductsTotal()
+	if total.Cmp(basket.Total) != 0 {
+		return Order{}, fmt.Errorf("%w: basket %s says %s, products add up to %s",
+			ErrPriceMismatch, basket.ID, basket.Total, total)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if customer.Cash.Cmp(total) < 0 {
+		return Order{}, fmt.Errorf("%w: customer %s has %s, basket %s needs %s",
+			ErrInsufficientFunds, customer.ID, customer.Cash, basket.ID, total)
+	}
+
+	r.next++
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	order := Order{
+		ID:         fmt.Sprintf("O%03d", r.next),
+		CustomerID: customer.ID,
+		BasketID:   basket.ID,
+		Lines:      append([]store.Product(nil), basket.Products...),
+		Total:      total,
+		CashBefore: customer.Cash,
+		CashAfter:  customer.Cash.Sub(total),
+		PlacedAt:   now(),
+	}
+	customer.Cash = order.CashAfter
+
+	return order, nil
+}
@@ -0,0 +1,147 @@
+package checkout
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ExamFolder/store"
+)
+
+func basket(id string, products ...store.Product) store.Basket {
+	b := store.Basket{ID: id, Products: products}
+	b.Total = b.ProductsTotal()
+	return b
+}
+
+func chips(quantity int) store.Product {
+	return store.Product{ID: "P1", Category: "Snack", Name: "Chips", Price: store.Major(5, ""), Quantity: quantity}
+}
+
+func TestCheckoutErrors(t *testing.T) {
+	mismatched := basket("B3", chips(2))
+	mismatched.Total = store.Major(9, "")
+
+	tests := []struct {
+		name   string
+		cash   int64
+		basket store.Basket
+		want   error
+	}{
+		{"empty basket", 100, basket("B1"), ErrEmptyBasket},
+		{"zero quantity", 100, basket("B2", chips(0)), ErrInvalidLine},
+		{"price mismatch", 100, mismatched, ErrPriceMismatch},
+		{"insufficient funds", 9, basket("B4", chips(2)), ErrInsufficientFunds},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			customer := store.Customer{ID: "C1", Cash: store.Major(test.cash, "")}
+			register := NewRegister()
+
+			order, err := register.Checkout(&customer, test.basket)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("error = %v, want %v", err, test.want)
+			}
+			if order.ID != "" {
+				t.Errorf("got order %+v on error", order)
+			}
+			if customer.Cash != store.Major(test.cash, "") {
+				t.Errorf("cash = %s after a rejected checkout, want %s untouched", customer.Cash, store.Major(test.cash, ""))
+			}
+		})
+	}
+}
+
+func TestCheckoutDebits(t *testing.T) {
+	placedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	register := &Register{Now: func() time.Time { return placedAt }}
+	customer := store.Customer{ID: "C1", Cash: store.Major(100, "")}
+
+	order, err := register.Checkout(&customer, basket("B1", chips(3)))
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	if customer.Cash != store.Major(85, "") {
+		t.Errorf("cash = %s, want 85.00", customer.Cash)
+	}
+	want := Order{
+		ID:         "O001",
+		CustomerID: "C1",
+		BasketID:   "B1",
+		Lines:      []store.Product{chips(3)},
+		Total:      store.Major(15, ""),
+		CashBefore: store.Major(100, ""),
+		CashAfter:  store.Major(85, ""),
+		PlacedAt:   placedAt,
+	}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %+v, want %+v", order, want)
+	}
+
+	receipt := order.Receipt()
+	for _, line := range []string{
+		"Order O001 (basket B1, customer C1)",
+		"2024-03-01T10:00:00Z",
+		"Chips",
+		"Total:      15.00",
+		"Cash:       100.00",
+		"Remaining:  85.00",
+	} {
+		if !strings.Contains(receipt, line) {
+			t.Errorf("receipt is missing %q:\n%s", line, receipt)
+		}
+	}
+
+	if second, err := register.Checkout(&customer, basket("B2", chips(1))); err != nil || second.ID != "O002" {
+		t.Errorf("second checkout = %+v, %v; want order O002", second, err)
+	}
+}
+
+// TestCheckoutConcurrent is meant for go test -race: many goroutines debit
+// one customer, and exactly as many succeed as the cash allows.
+func TestCheckoutConcurrent(t *testing.T) {
+	register := NewRegister()
+	customer := store.Customer{ID: "C1", Cash: store.Major(50, "")}
+
+	const attempts = 40
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, rejected := 0, 0
+	ids := make(map[string]bool)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			order, err := register.Checkout(&customer, basket("B1", chips(1)))
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+				ids[order.ID] = true
+			case errors.Is(err, ErrInsufficientFunds):
+				rejected++
+			default:
+				t.Errorf("Checkout: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 10 || rejected != attempts-10 {
+		t.Errorf("%d succeeded and %d rejected, want 10 and %d", succeeded, rejected, attempts-10)
+	}
+	if len(ids) != succeeded {
+		t.Errorf("%d distinct order IDs for %d orders", len(ids), succeeded)
+	}
+	if !customer.Cash.IsZero() {
+		t.Errorf("cash = %s, want 0.00", customer.Cash)
+	}
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"ExamFolder/query"
+	"ExamFolder/stats"
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// DefaultData is the dataset used when --data is not given: the copy
+// compiled into the binary, so exam works from any directory.
+const DefaultData = "embed://data.Json/store_data.json"
+
+// usageError marks errors caused by bad arguments rather than bad data.
+// An empty message means the problem was already reported by the flag set.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func usagef(format string, args ...any) error {
+	return usageError(fmt.Sprintf(format, args...))
+}
+
+type command struct {
+	name    string
+	summary string
+	usage   string
+	run     func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"report", "Run tasks and render them as a report", "report [--data FILE] [--tasks 2,6,12] [--series day|week|month] [--moving 3] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runReport},
+		{"task", "Print one or more tasks as console prose", "task [--data FILE] [N|NAME...] [--tasks 2,6,12] [--from DATE] [--to DATE] [--include-undated]", runTask},
+		{"customers", "List customers", "customers list [--data FILE] [--format text|json|csv|markdown]", runCustomers},
+		{"products", "Rank products by revenue", "products top [--data FILE] [--n 5] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runProducts},
+		{"catalog", "Show the product catalog derived from baskets", "catalog [list|conflicts|sold] [--data FILE] [--format text|json|csv|markdown]", runCatalog},
+		{"inventory", "Apply baskets to stock and report sell-through and reorders", "inventory [--stock FILE] [--data FILE] [--format text|json|csv|markdown]", runInventory},
+		{"rfm", "Score customers by recency, frequency and monetary value", "rfm [scores|export] [--as-of DATE] [--data FILE] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runRFM},
+		{"affinity", "Find products and categories bought together", "affinity [rules|itemsets|matrix] [--by product|category] [--min-support 0.05] [--min-confidence 0.5] [--max-size 3] [--data FILE] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runAffinity},
+		{"abc", "Classify products and customers into ABC (Pareto) classes", "abc [revenue|units|customers...] [--a 0.8] [--b 0.95] [--data FILE] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runABC},
+		{"stats", "Describe customers, orders or product lines statistically", "stats [PROJECTION...] [--histogram N] [--quantiles] [--data FILE] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown]", runStats},
+		{"query", "Answer an ad-hoc question such as 'revenue by category top 5'", "query [--data FILE] [--from DATE] [--to DATE] [--include-undated] [--format text|json|csv|markdown] 'QUERY'", runQuery},
+		{"repl", "Explore a dataset interactively (line editing and tab completion on Linux)", "repl [--data FILE]", runREPL},
+		{"validate", "Check a dataset and exit non-zero on errors", "validate [--data FILE]", runValidate},
+		{"promotions", "Apply promotion rules to every basket", "promotions --rules FILE [--data FILE] [--format text|json|csv|markdown]", runPromotions},
+		{"tax", "Break revenue and spending into net and tax", "tax [--rates FILE] [--data FILE] [--format text|json|csv|markdown]", runTax},
+		{"checkout", "Check out customers' baskets and print receipts", "checkout [--data FILE] CUSTOMER_ID[:BASKET_ID]...", runCheckout},
+		{"serve", "Serve customers and analytics over HTTP as JSON", "serve [--data FILE] [--addr :8080]", runServe},
+		{"help", "Show help for a command", "help [COMMAND]", runHelp},
+	}
+}
+
+// Run executes the command line (without the program name) and returns the
+// process exit code.
+func Run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return ExitUsage
+	}
+
+	name := args[0]
+	if name == "-h" || name == "--help" {
+		name = "help"
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "exam: unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return ExitUsage
+	}
+
+	err := cmd.run(args[1:])
+	var usage usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, flag.ErrHelp):
+		return ExitOK
+	case errors.As(err, &usage):
+		if usage != "" {
+			fmt.Fprintln(os.Stderr, "exam:", usage)
+			fmt.Fprintf(os.Stderr, "usage: exam %s\n", cmd.usage)
+		}
+		return ExitUsage
+	default:
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return ExitError
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: exam COMMAND [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Tasks:")
+	printTasks(w)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "exam help COMMAND" for the flags of a command.`)
+}
+
+func printTasks(w io.Writer) {
+	for _, info := range task.Tasks {
+		fmt.Fprintf(w, "  %2d  %-36s %s. %s\n", info.Number, info.Name, info.Title, info.Description)
+	}
+	fmt.Fprintln(w, "\nVariants (run by name):")
+	for _, info := range task.Variants {
+		fmt.Fprintf(w, "  %2d  %-36s %s. %s\n", info.Number, info.Name, info.Title, info.Description)
+	}
+}
+
+// newFlagSet creates the flag set of a command with the shared --data flag.
+func newFlagSet(cmd string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	data := flags.String("data", DefaultData, "dataset path or source URI (file://, stdin://, embed://)")
+	flags.Usage = func() {
+		c, _ := findCommand(cmd)
+		fmt.Fprintf(flags.Output(), "usage: exam %s\n\n%s.\n\nFlags:\n", c.usage, c.summary)
+		flags.PrintDefaults()
+		if cmd == "report" || cmd == "task" {
+			fmt.Fprintln(flags.Output(), "\nTasks:")
+			printTasks(flags.Output())
+		}
+		if cmd == "query" {
+			fmt.Fprintln(flags.Output(), "\nFields:")
+			for _, f := range query.Fields {
+				fmt.Fprintf(flags.Output(), "  %-13s %s\n", f.Name, f.Kind)
+			}
+		}
+		if cmd == "stats" {
+			fmt.Fprintln(flags.Output(), "\nProjections:")
+			for _, p := range stats.Projections {
+				fmt.Fprintf(flags.Output(), "  %-18s %s\n", p.Name, p.Description)
+			}
+		}
+	}
+	return flags, data
+}
+
+// windowFlags adds --from, --to and --include-undated to flags. The
+// returned function reads them once the flags are parsed.
+func windowFlags(flags *flag.FlagSet) func() (store.Window, error) {
+	from := flags.String("from", "", "only count orders placed on or after this date (YYYY-MM-DD or RFC 3339)")
+	to := flags.String("to", "", "only count orders placed up to this date, inclusive (YYYY-MM-DD or RFC 3339)")
+	includeUndated := flags.Bool("include-undated", false, "keep orders without a purchase time when --from or --to is given")
+	return func() (store.Window, error) {
+		window, err := store.ParseWindow(*from, *to)
+		if err != nil {
+			return store.Window{}, usagef("%v", err)
+		}
+		window.IncludeUndated = *includeUndated
+		return window, nil
+	}
+}
+
+// filterWindow applies the window, warning on stderr when it leaves out
+// undated orders, which would otherwise vanish from the results silently.
+func filterWindow(w store.Window, customers []store.Customer) []store.Customer {
+	warnUndated(w.Undated(customers))
+	return w.Filter(customers)
+}
+
+func warnUndated(undated int) {
+	if undated > 0 {
+		fmt.Fprintf(os.Stderr, "exam: warning: --from/--to left out %d undated orders; pass --include-undated to keep them\n", undated)
+	}
+}
+
+// parseArgs parses flags that may appear before, between or after
+// positional arguments, and returns the positional ones.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usageError("")
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// parseTaskList reads a comma separated list of task numbers like "2,6,12".
+func parseTaskList(list string) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, usagef("invalid task number %q", field)
+		}
+		if _, ok := task.TaskInfo(number); !ok {
+			return nil, usagef("unknown task %d", number)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,959 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+
+	"ExamFolder/affinity"
+	"ExamFolder/catalog"
+	"ExamFolder/checkout"
+	"ExamFolder/inventory"
+	"ExamFolder/promo"
+	"ExamFolder/query"
+	"ExamFolder/repl"
+	"ExamFolder/report"
+	"ExamFolder/rfm"
+	"ExamFolder/server"
+	"ExamFolder/stats"
+	"ExamFolder/store"
+	"ExamFolder/task"
+	"ExamFolder/tax"
+)
+
+const formatUsage = "output format: text, json, csv or markdown"
+
+func runReport(args []string) error {
+	flags, data := newFlagSet("report")
+	tasks := flags.String("tasks", "", "comma separated task numbers, e.g. 2,6,12 (default: all, or none with --series)")
+	series := flags.String("series", "", "add sales by day, week or month")
+	moving := flags.Int("moving", task.DefaultMovingWindow, "periods in the --series moving averages")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	numbers, err := parseTaskList(*tasks)
+	if err != nil {
+		return err
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+	var granularity task.Granularity
+	if *series != "" {
+		if granularity, err = task.ParseGranularity(*series); err != nil {
+			return usagef("%v", err)
+		}
+		if *moving < 1 {
+			return usagef("--moving must be at least 1")
+		}
+	}
+
+	if granularity == "" && report.Streamable(numbers) {
+		aggregate, err := aggregateData(*data, w)
+		if err != nil {
+			return err
+		}
+		r, err := report.BuildAggregate(aggregate, numbers)
+		if err != nil {
+			return err
+		}
+		return report.Render(os.Stdout, r, outputFormat)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+	customers = filterWindow(w, customers)
+
+	var r report.Report
+	if granularity == "" || len(numbers) > 0 {
+		if r, err = report.Build(customers, numbers); err != nil {
+			return err
+		}
+	}
+	if granularity != "" {
+		report.AddSeries(&r, task.SalesSeries(customers, granularity, *moving))
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runTask(args []string) error {
+	flags, data := newFlagSet("task")
+	tasks := flags.String("tasks", "", "comma separated task numbers, e.g. 2,6,12 (default: all)")
+	window := windowFlags(flags)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	numbers, err := parseTaskList(*tasks)
+	if err != nil {
+		return err
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, arg := range append(positional, numberStrings(numbers)...) {
+		if number, err := strconv.Atoi(arg); err == nil {
+			info, ok := task.TaskInfo(number)
+			if !ok {
+				return usagef("unknown task %d", number)
+			}
+			arg = info.Name
+		}
+		if _, ok := task.AnalysisByName(arg); !ok {
+			return usagef("unknown task %q", arg)
+		}
+		names = append(names, arg)
+	}
+	if len(names) == 0 {
+		for _, info := range task.Tasks {
+			names = append(names, info.Name)
+		}
+	}
+
+	if task.Streamable(names) {
+		aggregate, err := aggregateData(*data, w)
+		if err != nil {
+			return err
+		}
+		return task.PrintAggregate(os.Stdout, aggregate, names)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	return task.PrintAnalyses(os.Stdout, filterWindow(w, customers), names)
+}
+
+// aggregateData streams the dataset through the window into a
+// task.Aggregate, for tasks that need no more than that, so large exports
+// are never held in memory whole.
+func aggregateData(data string, w store.Window) (*task.Aggregate, error) {
+	source, err := store.OpenSource(data)
+	if err != nil {
+		return nil, err
+	}
+	streamer, ok := source.(store.Streamer)
+	if !ok {
+		return nil, fmt.Errorf("store: source %q does not support streaming", data)
+	}
+
+	windowed := w.Stream(streamer)
+	aggregate, err := task.AggregateStream(context.Background(), windowed)
+	if err != nil {
+		return nil, err
+	}
+	warnUndated(windowed.Undated)
+	return aggregate, nil
+}
+
+func numberStrings(numbers []int) []string {
+	strs := make([]string, len(numbers))
+	for i, number := range numbers {
+		strs[i] = strconv.Itoa(number)
+	}
+	return strs
+}
+
+func runCustomers(args []string) error {
+	flags, data := newFlagSet("customers")
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "list" {
+		return usagef("expected subcommand list")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	var r report.Report
+	s := r.Add("customers", "Customers", "customer_id", "first_name", "last_name", "cash", "orders", "basket_id", "product_lines", "basket_total")
+	for _, c := range customers {
+		s.AddRow(c.ID, c.FirstName, c.LastName, c.Cash, len(c.History()), c.Basket.ID, len(c.Basket.Products), c.Basket.Total)
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runProducts(args []string) error {
+	flags, data := newFlagSet("products")
+	n := flags.Int("n", 5, "number of products to show")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 || positional[0] != "top" {
+		return usagef("expected subcommand top")
+	}
+	if *n < 1 {
+		return usagef("--n must be at least 1")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	products := task.TopProductsByRevenue(filterWindow(w, customers), *n)
+
+	var r report.Report
+	s := r.Add("products-top", fmt.Sprintf("Top %d products by revenue", *n), "rank", "product_id", "name", "category", "units", "revenue")
+	for i, p := range products {
+		s.AddRow(i+1, p.ID, p.Name, p.Category, p.Units, p.Revenue)
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runCatalog(args []string) error {
+	flags, data := newFlagSet("catalog")
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	view := "list"
+	if len(positional) > 0 {
+		view = positional[0]
+	}
+	if len(positional) > 1 || (view != "list" && view != "conflicts" && view != "sold") {
+		return usagef("expected subcommand list, conflicts or sold")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	c := catalog.Build(customers)
+
+	var r report.Report
+	switch view {
+	case "list":
+		s := r.Add("catalog", "Product catalog", "product_id", "name", "category", "price", "lines", "units")
+		for _, e := range c.Entries {
+			s.AddRow(e.ID, e.Name, e.Category, e.Price, e.Lines, e.Units)
+		}
+	case "conflicts":
+		s := r.Add("catalog-conflicts", "Conflicting product definitions", "product_id", "field", "value", "lines")
+		for _, conflict := range c.Conflicts {
+			for _, variant := range conflict.Variants {
+				s.AddRow(conflict.ProductID, conflict.Field, variant.Value, variant.Lines)
+			}
+		}
+	case "sold":
+		sold := c.TotalSoldQuantity(task.AllProducts(customers))
+		s := r.Add("catalog-sold", "Units sold per product", "product_id", "name", "units")
+		for _, p := range sold.Products {
+			s.AddRow(p.ID, p.Name, p.Units)
+		}
+		s.AddRow("Total", "", sold.Total)
+	}
+
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+// DefaultStock is the stock file used when --stock is not given, compiled
+// into the binary like DefaultData.
+const DefaultStock = "embed://data.Json/inventory.json"
+
+func runInventory(args []string) error {
+	flags, data := newFlagSet("inventory")
+	stockFile := flags.String("stock", DefaultStock, "JSON stock file or URI (file://, embed://) with on_hand and reorder_point per product")
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	items, err := inventory.LoadStockFile(*stockFile)
+	if err != nil {
+		return err
+	}
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	levels := inventory.Apply(items, customers)
+
+	var r report.Report
+	products := r.Add("inventory-products", "Stock per product", "product_id", "name", "category", "on_hand", "sold", "remaining", "shortfall", "sell_through")
+	for _, l := range levels {
+		products.AddRow(l.ProductID, l.Name, l.Category, l.OnHand, l.Sold, l.Remaining, l.Shortfall, l.SellThrough)
+	}
+
+	categories := r.Add("inventory-categories", "Stock per category", "category", "products", "on_hand", "sold", "remaining", "stock_outs", "sell_through")
+	for _, c := range inventory.Categories(levels) {
+		categories.AddRow(c.Category, c.Products, c.OnHand, c.Sold, c.Remaining, c.StockOuts, c.SellThrough)
+	}
+
+	stockOuts := r.Add("inventory-stock-outs", "Stock-outs", "product_id", "name", "sold", "shortfall")
+	for _, l := range inventory.StockOuts(levels) {
+		stockOuts.AddRow(l.ProductID, l.Name, l.Sold, l.Shortfall)
+	}
+
+	reorders := r.Add("inventory-reorder", "Products to reorder", "product_id", "name", "remaining", "reorder_point")
+	for _, l := range inventory.Reorders(levels) {
+		reorders.AddRow(l.ProductID, l.Name, l.Remaining, l.ReorderPoint)
+	}
+
+	untracked := r.Add("inventory-untracked", "Sold but not in the stock file", "product_id", "name", "category", "sold")
+	for _, l := range inventory.Untracked(levels) {
+		untracked.AddRow(l.ProductID, l.Name, l.Category, l.Sold)
+	}
+
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runRFM(args []string) error {
+	flags, data := newFlagSet("rfm")
+	asOf := flags.String("as-of", "", "date recency is measured up to (default: the latest purchase)")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	view := "scores"
+	if len(positional) > 0 {
+		view = positional[0]
+	}
+	if len(positional) > 1 || (view != "scores" && view != "export") {
+		return usagef("expected subcommand scores or export")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+	reference, err := store.ParseWindow(*asOf, "")
+	if err != nil {
+		return usagef("--as-of: %v", err)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	results := rfm.Score(filterWindow(w, customers), reference.From)
+	if view == "export" {
+		return rfm.WriteCSV(os.Stdout, results)
+	}
+
+	var r report.Report
+	report.AddRFM(&r, results)
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runAffinity(args []string) error {
+	flags, data := newFlagSet("affinity")
+	defaults := affinity.DefaultOptions()
+	level := flags.String("by", string(defaults.Level), "items to mine: product or category")
+	minSupport := flags.Float64("min-support", defaults.MinSupport, "minimum share of baskets holding an itemset, 0 to 1")
+	minConfidence := flags.Float64("min-confidence", defaults.MinConfidence, "minimum confidence of a rule, 0 to 1")
+	maxSize := flags.Int("max-size", defaults.MaxSize, "largest itemset to mine, 0 for no limit")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	view := "rules"
+	if len(positional) > 0 {
+		view = positional[0]
+	}
+	if len(positional) > 1 || (view != "rules" && view != "itemsets" && view != "matrix") {
+		return usagef("expected subcommand rules, itemsets or matrix")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+	options := affinity.Options{MinSupport: *minSupport, MinConfidence: *minConfidence, MaxSize: *maxSize}
+	if options.Level, err = affinity.ParseLevel(*level); err != nil {
+		return usagef("%v", err)
+	}
+	if err := options.Validate(); err != nil {
+		return usagef("%v", err)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+	customers = filterWindow(w, customers)
+
+	var r report.Report
+	if view == "matrix" {
+		m := affinity.CategoryMatrix(customers)
+		s := r.Add("affinity-matrix", "Baskets holding both categories", append([]string{"category"}, m.Categories...)...)
+		for i, category := range m.Categories {
+			row := []any{category}
+			for _, count := range m.Counts[i] {
+				row = append(row, count)
+			}
+			s.AddRow(row...)
+		}
+		return report.Render(os.Stdout, r, outputFormat)
+	}
+
+	result, err := affinity.Mine(customers, options)
+	if err != nil {
+		return err
+	}
+
+	if view == "itemsets" {
+		s := r.Add("affinity-itemsets", fmt.Sprintf("Frequent itemsets over %d baskets", result.Transactions), "size", "items", "names", "count", "support")
+		for _, set := range result.Itemsets {
+			s.AddRow(len(set.Items), strings.Join(set.Items, ", "), strings.Join(set.Names, ", "), set.Count, set.Support)
+		}
+	} else {
+		s := r.Add("affinity-rules", fmt.Sprintf("Association rules over %d baskets", result.Transactions), "antecedent", "consequent", "count", "support", "confidence", "lift")
+		for _, rule := range result.Rules {
+			s.AddRow(strings.Join(rule.Antecedent.Names, ", "), strings.Join(rule.Consequent.Names, ", "), rule.Count, rule.Support, rule.Confidence, rule.Lift)
+		}
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runABC(args []string) error {
+	flags, data := newFlagSet("abc")
+	defaults := task.DefaultThresholds()
+	a := flags.Float64("a", defaults.A, "cumulative share at which class A ends, 0 to 1")
+	b := flags.Float64("b", defaults.B, "cumulative share at which class B ends, 0 to 1")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	views := []string{"revenue", "units", "customers"}
+	for _, view := range positional {
+		if !slices.Contains(views, view) {
+			return usagef("unknown view %q: expected revenue, units or customers", view)
+		}
+	}
+	if len(positional) > 0 {
+		views = positional
+	}
+	thresholds := task.Thresholds{A: *a, B: *b}
+	if err := thresholds.Validate(); err != nil {
+		return usagef("%v", err)
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+	customers = filterWindow(w, customers)
+
+	var r report.Report
+	for _, view := range views {
+		switch view {
+		case "revenue":
+			report.AddABC(&r, "abc-products-revenue", "ABC products by revenue", "revenue", task.ProductsABCByRevenue(customers, thresholds), true)
+		case "units":
+			report.AddABC(&r, "abc-products-units", "ABC products by units", "units", task.ProductsABCByUnits(customers, thresholds), false)
+		case "customers":
+			report.AddABC(&r, "abc-customers", "ABC customers by basket total", "basket_total", task.CustomersABC(customers, thresholds), true)
+		}
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runStats(args []string) error {
+	flags, data := newFlagSet("stats")
+	bins := flags.Int("histogram", 0, "also print a histogram with this many bins")
+	quantiles := flags.Bool("quantiles", false, "cut histogram bins at quantiles instead of equal widths")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	var projections []stats.Projection
+	for _, name := range positional {
+		projection, ok := stats.ProjectionByName(name)
+		if !ok {
+			return usagef("unknown projection %q", name)
+		}
+		projections = append(projections, projection)
+	}
+	if len(projections) == 0 {
+		projections = stats.Projections
+	}
+	if *bins < 0 {
+		return usagef("--histogram must not be negative")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+	customers = filterWindow(w, customers)
+
+	var r report.Report
+	summary := r.Add("stats-summary", "Descriptive statistics", "projection", "count", "mean", "median", "p90", "p95", "p99", "min", "max", "stddev")
+	for _, projection := range projections {
+		d := stats.Describe(projection.Values(customers))
+		summary.AddRow(projection.Name, d.Count, d.Mean, d.Median, d.P90, d.P95, d.P99, d.Min, d.Max, d.StdDev)
+	}
+
+	if *bins > 0 {
+		for _, projection := range projections {
+			values := projection.Values(customers)
+			histogram := stats.Histogram(values, *bins)
+			if *quantiles {
+				histogram = stats.QuantileHistogram(values, *bins)
+			}
+
+			s := r.Add("stats-histogram-"+projection.Name, "Histogram of "+projection.Name, "low", "high", "count")
+			for _, bin := range histogram {
+				s.AddRow(bin.Low, bin.High, bin.Count)
+			}
+		}
+	}
+
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runQuery(args []string) error {
+	flags, data := newFlagSet("query")
+	window := windowFlags(flags)
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return usagef("expected a query")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+	w, err := window()
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(positional, " ")
+	q, err := query.Compile(text)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	result, err := q.Run(filterWindow(w, customers))
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	var r report.Report
+	s := r.Add("query", text, result.Columns...)
+	for _, row := range result.Rows {
+		s.AddRow(row...)
+	}
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runREPL(args []string) error {
+	flags, data := newFlagSet("repl")
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	shell, err := repl.New(*data, os.Stdout)
+	if err != nil {
+		return err
+	}
+	return shell.Run(os.Stdin)
+}
+
+// errInvalidData is returned by validate when the dataset has errors; the
+// issues themselves are already printed.
+var errInvalidData = errors.New("dataset has validation errors")
+
+func runValidate(args []string) error {
+	flags, data := newFlagSet("validate")
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	issues := store.Validate(customers)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	if store.HasErrors(issues) {
+		return errInvalidData
+	}
+
+	fmt.Printf("%d customers OK\n", len(customers))
+	return nil
+}
+
+func runPromotions(args []string) error {
+	flags, data := newFlagSet("promotions")
+	rulesFile := flags.String("rules", "", "JSON or YAML (.yaml, .yml) file of promotion rules")
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	if *rulesFile == "" {
+		return usagef("--rules is required")
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	rules, err := promo.LoadRulesFile(*rulesFile)
+	if err != nil {
+		return err
+	}
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+	currency, err := store.Currency(customers)
+	if err != nil {
+		return err
+	}
+	if err := promo.CheckCurrency(rules, currency); err != nil {
+		return err
+	}
+
+	var r report.Report
+	baskets := r.Add("promotions-baskets", "Discounted baskets", "customer_id", "basket_id", "subtotal", "discount", "total")
+	discounts := r.Add("promotions-discounts", "Itemized discounts", "basket_id", "rule_id", "product_id", "description", "amount")
+	for _, customer := range customers {
+		for _, result := range promo.ApplyOrders(rules, customer) {
+			if len(result.Discounts) == 0 {
+				continue
+			}
+			baskets.AddRow(customer.ID, result.BasketID, result.Subtotal, result.Discount, result.Total)
+			for _, discount := range result.Discounts {
+				discounts.AddRow(result.BasketID, discount.RuleID, discount.ProductID, discount.Description, discount.Amount)
+			}
+		}
+	}
+
+	gross := make(map[string]store.Money)
+	for _, revenue := range task.CategoryRevenues(customers) {
+		gross[revenue.Category] = revenue.Revenue
+	}
+	categories := r.Add("promotions-category-revenue", "Category revenue after promotions", "category", "gross", "net")
+	for _, net := range promo.CategoryRevenues(customers, rules) {
+		categories.AddRow(net.Category, gross[net.Category], net.Revenue)
+	}
+
+	best := r.Add("promotions-task-12", "Task 12: Most profitable category after promotions", "category", "revenue")
+	if top, ok := promo.MostProfitableCategory(customers, rules); ok {
+		best.AddRow(top.Category, top.Revenue)
+	}
+
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+func runTax(args []string) error {
+	flags, data := newFlagSet("tax")
+	ratesFile := flags.String("rates", "", "JSON tax table (default: Food 1%, Beverage 10%, others 20%)")
+	format := flags.String("format", "text", formatUsage)
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+	outputFormat, err := report.ParseFormat(*format)
+	if err != nil {
+		return usagef("%v", err)
+	}
+
+	table := tax.DefaultTable()
+	if *ratesFile != "" {
+		if table, err = tax.LoadTableFile(*ratesFile); err != nil {
+			return err
+		}
+	}
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	var r report.Report
+	categories := r.Add("tax-category-revenue", "Category revenue with tax", "category", "rate", "net", "tax", "gross")
+	var total tax.Amounts
+	for _, c := range tax.CategoryRevenues(customers, table) {
+		categories.AddRow(c.Category, c.Rate.String(), c.Net, c.Tax, c.Gross)
+		total = total.Add(c.Amounts)
+	}
+	categories.AddRow("Total", "", total.Net, total.Tax, total.Gross)
+
+	spending := r.Add("tax-customer-spending", "Customer spending with tax", "customer_id", "first_name", "last_name", "net", "tax", "gross")
+	for _, c := range tax.CustomerSpending(customers, table) {
+		spending.AddRow(c.Customer.ID, c.Customer.FirstName, c.Customer.LastName, c.Net, c.Tax, c.Gross)
+	}
+
+	return report.Render(os.Stdout, r, outputFormat)
+}
+
+// errCheckoutFailed is returned when at least one checkout was rejected;
+// the reasons are already printed.
+var errCheckoutFailed = errors.New("some checkouts failed")
+
+func runCheckout(args []string) error {
+	flags, data := newFlagSet("checkout")
+
+	ids, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return usagef("expected at least one customer id or CUSTOMER_ID:BASKET_ID")
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	// Resolve every argument first so a repeat is rejected before anyone
+	// is debited.
+	type request struct {
+		customer int
+		basket   store.Basket
+	}
+	var requests []request
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		i, basket, err := checkoutOrder(customers, id)
+		if err != nil {
+			return err
+		}
+		key := customers[i].ID + ":" + basket.ID
+		if seen[key] {
+			return usagef("order %s of customer %s is named more than once", basket.ID, customers[i].ID)
+		}
+		seen[key] = true
+		requests = append(requests, request{i, basket})
+	}
+
+	register := checkout.NewRegister()
+	failed := false
+
+	for _, req := range requests {
+		i, basket := req.customer, req.basket
+		order, err := register.Checkout(&customers[i], basket)
+		if err != nil {
+			fmt.Println(err)
+			failed = true
+			continue
+		}
+		fmt.Print(order.Receipt())
+		fmt.Println()
+	}
+
+	if failed {
+		return errCheckoutFailed
+	}
+	return nil
+}
+
+// checkoutOrder finds the customer and the one order an argument names:
+// CUSTOMER_ID for a customer with a single order, else
+// CUSTOMER_ID:BASKET_ID. The consolidated basket is never checked out, as
+// it would debit a whole order history as one order.
+func checkoutOrder(customers []store.Customer, arg string) (int, store.Basket, error) {
+	id, basketID, named := strings.Cut(arg, ":")
+	i := slices.IndexFunc(customers, func(c store.Customer) bool { return c.ID == id })
+	if i < 0 {
+		return 0, store.Basket{}, fmt.Errorf("customer %s not found", id)
+	}
+
+	orders := customers[i].History()
+	if !named {
+		switch len(orders) {
+		case 0:
+			return 0, store.Basket{}, fmt.Errorf("customer %s has no orders", id)
+		case 1:
+			return i, orders[0], nil
+		default:
+			return 0, store.Basket{}, usagef("customer %s has %d orders; name one as %s:BASKET_ID", id, len(orders), id)
+		}
+	}
+
+	for _, order := range orders {
+		if order.ID == basketID {
+			return i, order, nil
+		}
+	}
+	return 0, store.Basket{}, fmt.Errorf("customer %s has no order %s", id, basketID)
+}
+
+func runServe(args []string) error {
+	flags, data := newFlagSet("serve")
+	addr := flags.String("addr", ":8080", "address to listen on")
+
+	positional, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usagef("unexpected argument %q", positional[0])
+	}
+
+	customers, err := store.ReadData(*data)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := &http.Server{Addr: *addr, Handler: server.New(customers)}
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+
+	fmt.Fprintf(os.Stderr, "serving %d customers on %s\n", len(customers), *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func runHelp(args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return nil
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return usagef("unknown command %q", args[0])
+	}
+	if cmd.name == "help" {
+		fmt.Printf("usage: exam %s\n", cmd.usage)
+		return nil
+	}
+
+	return cmd.run([]string{"-h"})
+}
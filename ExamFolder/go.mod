@@ -1,3 +1,3 @@
-module main
+module ExamFolder
 
 go 1.21.3
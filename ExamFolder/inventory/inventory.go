@@ -0,0 +1,228 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"ExamFolder/catalog"
+	"ExamFolder/store"
+)
+
+// Item is the stock held of one product before any basket is applied.
+type Item struct {
+	ProductID    string `json:"product_id"`
+	OnHand       int    `json:"on_hand"`
+	ReorderPoint int    `json:"reorder_point"`
+}
+
+// Validate reports items that cannot be tracked.
+func (i Item) Validate() error {
+	if i.ProductID == "" {
+		return fmt.Errorf("inventory: item has no product_id")
+	}
+	if i.OnHand < 0 || i.ReorderPoint < 0 {
+		return fmt.Errorf("inventory: item %s: on_hand and reorder_point must not be negative", i.ProductID)
+	}
+	return nil
+}
+
+// LoadStock reads a JSON array of items and validates each one. A product
+// listed twice is an error.
+func LoadStock(r io.Reader) ([]Item, error) {
+	var items []Item
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if err := item.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("inventory: product %s is listed more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+	}
+	return items, nil
+}
+
+// LoadStockFile reads items from a JSON file path, or a file:// or
+// embed:// URI.
+func LoadStockFile(path string) ([]Item, error) {
+	file, err := store.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadStock(file)
+}
+
+// Level is the stock of one product after baskets were applied. Products
+// sold but missing from the stock file are reported with Tracked false and
+// no opening stock. Shortfall counts units sold beyond what was on hand.
+type Level struct {
+	ProductID    string  `json:"product_id"`
+	Name         string  `json:"name"`
+	Category     string  `json:"category"`
+	Tracked      bool    `json:"tracked"`
+	OnHand       int     `json:"on_hand"`
+	Sold         int     `json:"sold"`
+	Remaining    int     `json:"remaining"`
+	Shortfall    int     `json:"shortfall"`
+	ReorderPoint int     `json:"reorder_point"`
+	SellThrough  float64 `json:"sell_through"`
+}
+
+// StockOut reports whether nothing is left of a tracked product. Untracked
+// products have no known stock, so they are never stock-outs.
+func (l Level) StockOut() bool {
+	return l.Tracked && l.Remaining == 0
+}
+
+// NeedsReorder reports whether the remaining stock of a tracked product is
+// at or below the reorder point.
+func (l Level) NeedsReorder() bool {
+	return l.Tracked && l.Remaining <= l.ReorderPoint
+}
+
+// CategoryLevel sums the levels of one category.
+type CategoryLevel struct {
+	Category    string  `json:"category"`
+	Products    int     `json:"products"`
+	OnHand      int     `json:"on_hand"`
+	Sold        int     `json:"sold"`
+	Remaining   int     `json:"remaining"`
+	StockOuts   int     `json:"stock_outs"`
+	SellThrough float64 `json:"sell_through"`
+}
+
+// sellThrough is the share of opening stock that was sold, capped at 1.
+func sellThrough(sold, onHand int) float64 {
+	if onHand <= 0 {
+		if sold > 0 {
+			return 1
+		}
+		return 0
+	}
+	return min(float64(sold)/float64(onHand), 1)
+}
+
+// Apply takes every basket's quantities out of stock. Levels are ordered by
+// product ID; names and categories come from the catalog built from the
+// baskets.
+func Apply(items []Item, customers []store.Customer) []Level {
+	c := catalog.Build(customers)
+
+	var levels []Level
+	index := make(map[string]int)
+	for _, item := range items {
+		index[item.ProductID] = len(levels)
+		levels = append(levels, Level{
+			ProductID:    item.ProductID,
+			Tracked:      true,
+			OnHand:       item.OnHand,
+			ReorderPoint: item.ReorderPoint,
+		})
+	}
+
+	for _, entry := range c.Entries {
+		i, ok := index[entry.ID]
+		if !ok {
+			i = len(levels)
+			index[entry.ID] = i
+			levels = append(levels, Level{ProductID: entry.ID})
+		}
+		levels[i].Name = entry.Name
+		levels[i].Category = entry.Category
+		levels[i].Sold = entry.Units
+	}
+
+	for i := range levels {
+		level := &levels[i]
+		level.Remaining = max(level.OnHand-level.Sold, 0)
+		level.Shortfall = max(level.Sold-level.OnHand, 0)
+		level.SellThrough = sellThrough(level.Sold, level.OnHand)
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].ProductID < levels[j].ProductID
+	})
+	return levels
+}
+
+// Categories sums levels per category, ordered by category name. Products
+// never sold have no known category and are left out.
+func Categories(levels []Level) []CategoryLevel {
+	var categories []CategoryLevel
+	index := make(map[string]int)
+
+	for _, level := range levels {
+		if level.Category == "" {
+			continue
+		}
+
+		i, ok := index[level.Category]
+		if !ok {
+			i = len(categories)
+			index[level.Category] = i
+			categories = append(categories, CategoryLevel{Category: level.Category})
+		}
+
+		category := &categories[i]
+		category.Products++
+		category.OnHand += level.OnHand
+		category.Sold += level.Sold
+		category.Remaining += level.Remaining
+		if level.StockOut() {
+			category.StockOuts++
+		}
+	}
+
+	for i := range categories {
+		categories[i].SellThrough = sellThrough(categories[i].Sold, categories[i].OnHand)
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category < categories[j].Category
+	})
+	return categories
+}
+
+// StockOuts returns the tracked levels with nothing left, in level order.
+func StockOuts(levels []Level) []Level {
+	var out []Level
+	for _, level := range levels {
+		if level.StockOut() {
+			out = append(out, level)
+		}
+	}
+	return out
+}
+
+// Reorders returns the tracked levels at or below their reorder point, in
+// level order.
+func Reorders(levels []Level) []Level {
+	var out []Level
+	for _, level := range levels {
+		if level.NeedsReorder() {
+			out = append(out, level)
+		}
+	}
+	return out
+}
+
+// Untracked returns the levels of products sold but missing from the stock
+// file, in level order.
+func Untracked(levels []Level) []Level {
+	var out []Level
+	for _, level := range levels {
+		if !level.Tracked {
+			out = append(out, level)
+		}
+	}
+	return out
+}
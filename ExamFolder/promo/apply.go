@@ -0,0 +1,209 @@
+package promo
+
+import (
+	"fmt"
+	"sort"
+
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// Discount is one itemized saving. ProductID is empty for basket coupons.
+type Discount struct {
+	RuleID      string      `json:"rule_id"`
+	ProductID   string      `json:"product_id,omitempty"`
+	Description string      `json:"description"`
+	Amount      store.Money `json:"amount"`
+}
+
+// Line is a basket line after promotions. Discount includes its share of
+// any basket coupon, so Net always sums to the basket Total.
+type Line struct {
+	Product  store.Product `json:"product"`
+	Gross    store.Money   `json:"gross"`
+	Discount store.Money   `json:"discount"`
+	Net      store.Money   `json:"net"`
+}
+
+// Result is a basket evaluated against a set of rules.
+type Result struct {
+	BasketID  string      `json:"basket_id"`
+	Lines     []Line      `json:"lines"`
+	Discounts []Discount  `json:"discounts"`
+	Subtotal  store.Money `json:"subtotal"`
+	Discount  store.Money `json:"discount"`
+	Total     store.Money `json:"total"`
+}
+
+// Apply evaluates rules against a basket. Line rules apply in order to each
+// line's gross amount and never discount a line below zero; spend-over
+// coupons are then checked against the discounted total and spread over the
+// lines in proportion to their net amounts.
+func Apply(rules []Rule, basket store.Basket) Result {
+	result := Result{BasketID: basket.ID}
+
+	for _, product := range basket.Products {
+		gross := product.LineTotal()
+		result.Lines = append(result.Lines, Line{Product: product, Gross: gross, Net: gross})
+		result.Subtotal = result.Subtotal.Add(gross)
+	}
+
+	for _, rule := range rules {
+		for i := range result.Lines {
+			line := &result.Lines[i]
+			if !rule.matches(line.Product) {
+				continue
+			}
+
+			amount, description := lineDiscount(rule, line.Product)
+			if amount.Cmp(line.Net) > 0 {
+				amount = line.Net
+			}
+			if amount.IsZero() || amount.IsNegative() {
+				continue
+			}
+
+			line.Discount = line.Discount.Add(amount)
+			line.Net = line.Net.Sub(amount)
+			result.Discounts = append(result.Discounts, Discount{
+				RuleID:      rule.ID,
+				ProductID:   line.Product.ID,
+				Description: description,
+				Amount:      amount,
+			})
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Kind != SpendOver {
+			continue
+		}
+
+		net := netTotal(result.Lines)
+		if net.Cmp(rule.Threshold) < 0 || net.IsZero() {
+			continue
+		}
+
+		amount := rule.Amount
+		description := fmt.Sprintf("%s off baskets over %s", rule.Amount, rule.Threshold)
+		if rule.Percent > 0 {
+			amount = net.MulFrac(int64(rule.Percent), 100)
+			description = fmt.Sprintf("%d%% off baskets over %s", rule.Percent, rule.Threshold)
+		}
+		if amount.Cmp(net) > 0 {
+			amount = net
+		}
+
+		spread(result.Lines, amount, net)
+		result.Discounts = append(result.Discounts, Discount{
+			RuleID:      rule.ID,
+			Description: description,
+			Amount:      amount,
+		})
+	}
+
+	result.Total = netTotal(result.Lines)
+	result.Discount = result.Subtotal.Sub(result.Total)
+	return result
+}
+
+func lineDiscount(rule Rule, product store.Product) (store.Money, string) {
+	switch rule.Kind {
+	case MultiBuy:
+		free := product.Quantity / rule.Buy * (rule.Buy - rule.Pay)
+		return product.Price.Mul(free), fmt.Sprintf("%d for %d on %s", rule.Buy, rule.Pay, product.Name)
+	case PercentOff:
+		return product.LineTotal().MulFrac(int64(rule.Percent), 100), fmt.Sprintf("%d%% off %s", rule.Percent, product.Name)
+	default:
+		return store.Money{}, ""
+	}
+}
+
+func netTotal(lines []Line) store.Money {
+	var total store.Money
+	for _, line := range lines {
+		total = total.Add(line.Net)
+	}
+	return total
+}
+
+// spread allocates amount over the lines pro rata to their net, giving the
+// rounding remainder to the last line that still has a positive net.
+func spread(lines []Line, amount, net store.Money) {
+	remaining := amount
+	last := -1
+	for i := range lines {
+		if lines[i].Net.Amount > 0 {
+			last = i
+		}
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		if line.Net.Amount <= 0 {
+			continue
+		}
+
+		share := remaining
+		if i != last {
+			share = amount.MulFrac(line.Net.Amount, net.Amount)
+			if share.Cmp(line.Net) > 0 {
+				share = line.Net
+			}
+		}
+
+		line.Discount = line.Discount.Add(share)
+		line.Net = line.Net.Sub(share)
+		remaining = remaining.Sub(share)
+	}
+}
+
+// ApplyOrders evaluates rules against each of the customer's orders on its
+// own, so spend-over thresholds are never met by adding orders together.
+func ApplyOrders(rules []Rule, customer store.Customer) []Result {
+	var results []Result
+	for _, order := range customer.History() {
+		results = append(results, Apply(rules, order))
+	}
+	return results
+}
+
+// CategoryRevenues is task.CategoryRevenues net of promotions, ordered by
+// category name. Promotions apply per order.
+func CategoryRevenues(customers []store.Customer, rules []Rule) []task.CategoryRevenue {
+	var revenues []task.CategoryRevenue
+	index := make(map[string]int)
+
+	for _, customer := range customers {
+		for _, result := range ApplyOrders(rules, customer) {
+			for _, line := range result.Lines {
+				category := line.Product.Category
+				i, ok := index[category]
+				if !ok {
+					i = len(revenues)
+					index[category] = i
+					revenues = append(revenues, task.CategoryRevenue{Category: category})
+				}
+				revenues[i].Revenue = revenues[i].Revenue.Add(line.Net)
+			}
+		}
+	}
+
+	sort.Slice(revenues, func(i, j int) bool {
+		return revenues[i].Category < revenues[j].Category
+	})
+	return revenues
+}
+
+// MostProfitableCategory is task.MostProfitableCategory net of promotions.
+func MostProfitableCategory(customers []store.Customer, rules []Rule) (task.CategoryRevenue, bool) {
+	top := task.TopN(CategoryRevenues(customers, rules), 1, func(c task.CategoryRevenue) int64 {
+		return c.Revenue.Amount
+	}, func(c task.CategoryRevenue) (string, string) {
+		return c.Category, c.Category
+	})
+	if len(top) == 0 {
+		return task.CategoryRevenue{}, false
+	}
+	return top[0], true
+}
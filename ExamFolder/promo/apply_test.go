@@ -0,0 +1,170 @@
+package promo
+
+import (
+	"reflect"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func product(id, category string, price int64, quantity int) store.Product {
+	return store.Product{ID: id, Category: category, Name: "Name" + id, Price: store.Major(price, ""), Quantity: quantity}
+}
+
+func basket(id string, products ...store.Product) store.Basket {
+	b := store.Basket{ID: id, Products: products}
+	b.Total = b.ProductsTotal()
+	return b
+}
+
+func TestApplyOrdersKeepsThresholdPerOrder(t *testing.T) {
+	rules := []Rule{{ID: "SPEND-100", Kind: SpendOver, Threshold: store.Major(100, ""), Amount: store.Major(10, "")}}
+	customer := store.Customer{ID: "C1", Orders: []store.Basket{
+		basket("B1", product("P1", "Tech", 60, 1)),
+		basket("B2", product("P2", "Tech", 50, 1)),
+	}}
+	customer.Basket = store.Consolidate(customer.Orders)
+	if customer.Basket.Total != store.Major(110, "") {
+		t.Fatalf("consolidated total = %s, want 110", customer.Basket.Total)
+	}
+
+	results := ApplyOrders(rules, customer)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want one per order", len(results))
+	}
+	for _, result := range results {
+		if len(result.Discounts) != 0 {
+			t.Errorf("basket %s: got discounts %+v, want none below the threshold", result.BasketID, result.Discounts)
+		}
+	}
+
+	revenues := CategoryRevenues([]store.Customer{customer}, rules)
+	if len(revenues) != 1 || revenues[0].Revenue != store.Major(110, "") {
+		t.Errorf("CategoryRevenues = %+v, want Tech at 110 undiscounted", revenues)
+	}
+}
+
+func TestApply(t *testing.T) {
+	threeForTwo := Rule{ID: "3FOR2", Kind: MultiBuy, Category: "Snack", Buy: 3, Pay: 2}
+	tenOffBakery := Rule{ID: "BAKERY-10", Kind: PercentOff, Category: "Bakery", Percent: 10}
+	freeBakery := Rule{ID: "BAKERY-100", Kind: PercentOff, Category: "Bakery", Percent: 100}
+	spend100 := Rule{ID: "SPEND-100", Kind: SpendOver, Threshold: store.Major(100, ""), Amount: store.Major(10, "")}
+	spend100Percent := Rule{ID: "SPEND-100-10", Kind: SpendOver, Threshold: store.Major(100, ""), Percent: 10}
+
+	tests := []struct {
+		name      string
+		rules     []Rule
+		products  []store.Product
+		discounts []int64 // per line, in minor units
+		applied   []string
+	}{
+		{
+			name:      "3 for 2 frees one unit per three",
+			rules:     []Rule{threeForTwo},
+			products:  []store.Product{product("P1", "Snack", 10, 7), product("P2", "Tech", 10, 3)},
+			discounts: []int64{2000, 0},
+			applied:   []string{"3FOR2"},
+		},
+		{
+			name:      "3 for 2 below three units",
+			rules:     []Rule{threeForTwo},
+			products:  []store.Product{product("P1", "Snack", 10, 2)},
+			discounts: []int64{0},
+		},
+		{
+			name:      "percent off rounds to the minor unit",
+			rules:     []Rule{tenOffBakery},
+			products:  []store.Product{{ID: "P1", Category: "Bakery", Price: store.Minor(333, ""), Quantity: 1}},
+			discounts: []int64{33},
+			applied:   []string{"BAKERY-10"},
+		},
+		{
+			name:      "line rules never go below zero",
+			rules:     []Rule{freeBakery, {ID: "ALL-3FOR2", Kind: MultiBuy, Buy: 3, Pay: 2}},
+			products:  []store.Product{product("P1", "Bakery", 10, 3)},
+			discounts: []int64{3000},
+			applied:   []string{"BAKERY-100"},
+		},
+		{
+			name:      "spend over spreads pro rata",
+			rules:     []Rule{spend100},
+			products:  []store.Product{product("P1", "Tech", 60, 1), product("P2", "Tech", 40, 1)},
+			discounts: []int64{600, 400},
+			applied:   []string{"SPEND-100"},
+		},
+		{
+			name:      "spend over gives the rounding remainder to the last line",
+			rules:     []Rule{{ID: "SPEND-3", Kind: SpendOver, Threshold: store.Major(3, ""), Amount: store.Major(1, "")}},
+			products:  []store.Product{product("P1", "Tech", 1, 1), product("P2", "Tech", 1, 1), product("P3", "Tech", 1, 1)},
+			discounts: []int64{33, 33, 34},
+			applied:   []string{"SPEND-3"},
+		},
+		{
+			name:      "spend over percent",
+			rules:     []Rule{spend100Percent},
+			products:  []store.Product{product("P1", "Tech", 250, 1)},
+			discounts: []int64{2500},
+			applied:   []string{"SPEND-100-10"},
+		},
+		{
+			name:      "spend over checks the total after line discounts",
+			rules:     []Rule{tenOffBakery, spend100},
+			products:  []store.Product{product("P1", "Bakery", 100, 1)},
+			discounts: []int64{1000},
+			applied:   []string{"BAKERY-10"},
+		},
+		{
+			name:      "spend over skips fully discounted lines",
+			rules:     []Rule{freeBakery, spend100},
+			products:  []store.Product{product("P1", "Bakery", 50, 1), product("P2", "Tech", 200, 1)},
+			discounts: []int64{5000, 1000},
+			applied:   []string{"BAKERY-100", "SPEND-100"},
+		},
+		{
+			name:      "spend over is capped at the basket",
+			rules:     []Rule{{ID: "SPEND-0", Kind: SpendOver, Amount: store.Major(50, "")}},
+			products:  []store.Product{product("P1", "Tech", 20, 1)},
+			discounts: []int64{2000},
+			applied:   []string{"SPEND-0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := Apply(test.rules, basket("B1", test.products...))
+
+			var subtotal, discount, net int64
+			for i, line := range result.Lines {
+				if line.Discount.Amount != test.discounts[i] {
+					t.Errorf("line %s discount = %s, want %s", line.Product.ID, line.Discount, store.Minor(test.discounts[i], ""))
+				}
+				if line.Gross.Sub(line.Discount) != line.Net {
+					t.Errorf("line %s: gross %s - discount %s != net %s", line.Product.ID, line.Gross, line.Discount, line.Net)
+				}
+				subtotal += line.Gross.Amount
+				discount += test.discounts[i]
+				net += line.Net.Amount
+			}
+
+			if result.Subtotal.Amount != subtotal || result.Discount.Amount != discount || result.Total.Amount != net {
+				t.Errorf("subtotal/discount/total = %s/%s/%s, want %s/%s/%s", result.Subtotal, result.Discount, result.Total,
+					store.Minor(subtotal, ""), store.Minor(discount, ""), store.Minor(net, ""))
+			}
+
+			var applied []string
+			var itemized int64
+			for _, d := range result.Discounts {
+				if len(applied) == 0 || applied[len(applied)-1] != d.RuleID {
+					applied = append(applied, d.RuleID)
+				}
+				itemized += d.Amount.Amount
+			}
+			if !reflect.DeepEqual(applied, test.applied) {
+				t.Errorf("applied rules %v, want %v", applied, test.applied)
+			}
+			if itemized != discount {
+				t.Errorf("itemized discounts sum to %s, want %s", store.Minor(itemized, ""), store.Minor(discount, ""))
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package promo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ExamFolder/store"
+)
+
+type Kind string
+
+const (
+	// MultiBuy gives "buy N, pay M" on matching lines, e.g. 3 for 2.
+	MultiBuy Kind = "multi-buy"
+	// PercentOff takes a percentage off matching lines.
+	PercentOff Kind = "percent-off"
+	// SpendOver is a basket coupon: once the basket, after line discounts,
+	// reaches Threshold, take Amount (or Percent) off.
+	SpendOver Kind = "spend-over"
+)
+
+// Rule is one promotion definition as stored in a rules file. Line rules
+// match on Category and/or ProductID; an empty matcher matches every line.
+type Rule struct {
+	ID        string      `json:"id"`
+	Kind      Kind        `json:"type"`
+	Category  string      `json:"category,omitempty"`
+	ProductID string      `json:"product_id,omitempty"`
+	Buy       int         `json:"buy,omitempty"`
+	Pay       int         `json:"pay,omitempty"`
+	Percent   int         `json:"percent,omitempty"`
+	Threshold store.Money `json:"threshold,omitempty"`
+	Amount    store.Money `json:"amount,omitempty"`
+}
+
+// Validate reports rules that cannot be evaluated.
+func (r Rule) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("promo: rule has no id")
+	}
+
+	switch r.Kind {
+	case MultiBuy:
+		if r.Buy < 1 || r.Pay < 0 || r.Pay >= r.Buy {
+			return fmt.Errorf("promo: rule %s: multi-buy needs buy > pay >= 0", r.ID)
+		}
+	case PercentOff:
+		if r.Percent < 1 || r.Percent > 100 {
+			return fmt.Errorf("promo: rule %s: percent must be between 1 and 100", r.ID)
+		}
+	case SpendOver:
+		if r.Amount.IsZero() == (r.Percent == 0) {
+			return fmt.Errorf("promo: rule %s: spend-over needs either amount or percent", r.ID)
+		}
+		if r.Amount.IsNegative() || r.Threshold.IsNegative() || r.Percent < 0 || r.Percent > 100 {
+			return fmt.Errorf("promo: rule %s: spend-over values must be positive", r.ID)
+		}
+	default:
+		return fmt.Errorf("promo: rule %s: unknown type %q", r.ID, r.Kind)
+	}
+
+	return nil
+}
+
+func (r Rule) matches(product store.Product) bool {
+	return (r.Category == "" || r.Category == product.Category) &&
+		(r.ProductID == "" || r.ProductID == product.ID)
+}
+
+// CheckCurrency reports rule amounts in another currency than the
+// dataset's, or than each other's, since those cannot be compared with or
+// taken off basket totals. An empty currency matches any.
+func CheckCurrency(rules []Rule, currency string) error {
+	for _, rule := range rules {
+		for _, amount := range []store.Money{rule.Threshold, rule.Amount} {
+			switch {
+			case amount.Currency == "" || amount.Currency == currency:
+			case currency == "":
+				currency = amount.Currency
+			default:
+				return fmt.Errorf("promo: rule %s: amount in %s does not match %s", rule.ID, amount.Currency, currency)
+			}
+		}
+	}
+	return nil
+}
+
+// LoadRules reads a JSON array of rules and validates each one.
+func LoadRules(reader io.Reader) ([]Rule, error) {
+	var rules []Rule
+	if err := json.NewDecoder(reader).Decode(&rules); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// LoadRulesFile reads rules from a JSON file, or a YAML one when the name
+// ends in .yaml or .yml.
+func LoadRulesFile(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return LoadRulesYAML(file)
+	default:
+		return LoadRules(file)
+	}
+}
@@ -0,0 +1,241 @@
+package promo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LoadRulesYAML reads rules written as a YAML list of mappings, with the
+// same keys as the JSON form, and validates each one:
+//
+//   - id: SNACK-3FOR2
+//     type: multi-buy
+//     category: Snack
+//     buy: 3
+//     pay: 2
+//
+// Only block-style YAML is understood: nested mappings and lists, plain or
+// quoted scalars, and # comments. Amounts may be nested mappings with
+// amount and currency keys.
+func LoadRulesYAML(reader io.Reader) ([]Rule, error) {
+	value, err := parseYAML(reader)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	// The parsed tree is plain maps, lists and scalars, so it goes through
+	// the JSON decoding and checks LoadRules already has.
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRules(strings.NewReader(string(data)))
+}
+
+// parseYAML reads a document into maps, lists and scalars, or nil when it
+// is empty.
+func parseYAML(reader io.Reader) (any, error) {
+	lines, err := yamlLines(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return value, nil
+}
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlLines drops blank lines, comments and document markers, and measures
+// the indentation of the rest.
+func yamlLines(reader io.Reader) ([]yamlLine, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(reader)
+	for number := 1; scanner.Scan(); number++ {
+		raw := scanner.Text()
+		if strings.Contains(raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))], "\t") {
+			return nil, fmt.Errorf("promo: yaml line %d: tabs are not allowed for indentation", number)
+		}
+
+		text := strings.TrimRight(stripComment(raw), " ")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{number: number, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	return lines, scanner.Err()
+}
+
+// stripComment cuts a # comment that starts the line or follows a space,
+// outside quotes.
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	number := p.lines[len(p.lines)-1].number
+	if p.pos < len(p.lines) {
+		number = p.lines[p.pos].number
+	}
+	return fmt.Errorf("promo: yaml line %d: %s", number, fmt.Sprintf(format, args...))
+}
+
+func isListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// block reads the list or mapping whose lines sit at indent.
+func (p *yamlParser) block(indent int) (any, error) {
+	if isListItem(p.lines[p.pos].text) {
+		return p.list(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) list(indent int) ([]any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isListItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		var item any
+		var err error
+		switch {
+		case rest == "":
+			p.pos++
+			item, err = p.nested(indent)
+		case !strings.HasPrefix(rest, "{") && (strings.Contains(rest, ": ") || strings.HasSuffix(rest, ":")):
+			// "- key: value" opens a mapping indented past the dash.
+			p.lines[p.pos] = yamlLine{number: line.number, indent: line.indent + len(line.text) - len(rest), text: rest}
+			item, err = p.mapping(p.lines[p.pos].indent)
+		default:
+			p.pos++
+			item, err = scalar(rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	values := make(map[string]any)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		text := p.lines[p.pos].text
+		if isListItem(text) {
+			return nil, p.errorf("list item where a key was expected")
+		}
+
+		key, value, ok := strings.Cut(text, ":")
+		if !ok || value != "" && value[0] != ' ' {
+			return nil, p.errorf("expected key: value, found %q", text)
+		}
+		key = strings.TrimSpace(key)
+		if _, dup := values[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		var err error
+		if value = strings.TrimSpace(value); value == "" {
+			values[key], err = p.nested(indent)
+		} else {
+			values[key], err = scalar(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// nested reads the value under a key or dash that has nothing after it:
+// a deeper block, a list at the same indentation as its key, or null.
+func (p *yamlParser) nested(indent int) (any, error) {
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	switch {
+	case next.indent > indent:
+		return p.block(next.indent)
+	case next.indent == indent && isListItem(next.text):
+		return p.list(indent)
+	default:
+		return nil, nil
+	}
+}
+
+// yamlNumber matches the numbers JSON can carry; anything else is a string.
+var yamlNumber = regexp.MustCompile(`^-?(0|[1-9]\d*)(\.\d+)?([eE][-+]?\d+)?$`)
+
+// scalar reads a plain or quoted value. Numbers stay exact as json.Number
+// so amounts never pass through float64.
+func scalar(text string) (any, error) {
+	switch {
+	case strings.HasPrefix(text, "{") || strings.HasPrefix(text, "["):
+		return nil, fmt.Errorf("promo: yaml flow collections are not supported: %s", text)
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("promo: yaml string %s: %w", text, err)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("promo: yaml string %s is not terminated", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "~" || text == "null":
+		return nil, nil
+	case text == "true" || text == "false":
+		return text == "true", nil
+	case yamlNumber.MatchString(text):
+		return json.Number(text), nil
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,114 @@
+package promo
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  any
+	}{
+		{"empty", "# only a comment\n\n---\n", nil},
+		{"mapping", "a: 1\nb: text\n", map[string]any{"a": json.Number("1"), "b": "text"}},
+		{"list of scalars", "- 1\n- two\n- true\n- ~\n", []any{json.Number("1"), "two", true, nil}},
+		{"list of mappings", "- id: A\n  buy: 3\n- id: B\n", []any{
+			map[string]any{"id": "A", "buy": json.Number("3")},
+			map[string]any{"id": "B"},
+		}},
+		{"nested mapping", "amount:\n  amount: 5.5\n  currency: UZS\n", map[string]any{
+			"amount": map[string]any{"amount": json.Number("5.5"), "currency": "UZS"},
+		}},
+		{"list under key at same indentation", "items:\n- a\n- b\nnext: 1\n", map[string]any{
+			"items": []any{"a", "b"}, "next": json.Number("1"),
+		}},
+		{"dash on its own line", "-\n  id: A\n", []any{map[string]any{"id": "A"}}},
+		{"key with no value", "a:\nb: 1\n", map[string]any{"a": nil, "b": json.Number("1")}},
+		{"quoted scalars", `a: "x # not a comment"` + "\nb: 'it''s'\nc: \"tab\\tq\"\n", map[string]any{
+			"a": "x # not a comment", "b": "it's", "c": "tab\tq",
+		}},
+		{"comments", "# head\na: 1 # trailing\nb: x#y\n", map[string]any{"a": json.Number("1"), "b": "x#y"}},
+		{"numbers stay exact", "a: 0.1\nb: -2e3\nc: 012\nd: 1.\n", map[string]any{
+			"a": json.Number("0.1"), "b": json.Number("-2e3"), "c": "012", "d": "1.",
+		}},
+		{"indented document", "  - a\n  - b\n", []any{"a", "b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseYAML(strings.NewReader(test.input))
+			if err != nil {
+				t.Fatalf("parseYAML: %v", err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %#v, want %#v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"tab indentation", "a:\n\tb: 1\n", "line 2: tabs"},
+		{"duplicate key", "a: 1\na: 2\n", `line 2: duplicate key "a"`},
+		{"missing colon", "a: 1\njust text\n", "line 2: expected key: value"},
+		{"no space after colon", "a:1\n", "line 1: expected key: value"},
+		{"list item in mapping", "a: 1\n- b\n", "line 2: list item where a key was expected"},
+		{"dedent below document", "  a: 1\nb: 2\n", "line 2: unexpected indentation"},
+		{"deeper without parent", "a: 1\n  b: 2\n", "line 2: unexpected indentation"},
+		{"flow mapping", "- {id: A}\n", "flow collections are not supported"},
+		{"flow list", "a: [1, 2]\n", "flow collections are not supported"},
+		{"unterminated single quote", "a: 'open\n", "is not terminated"},
+		{"bad double quote", `a: "open` + "\n", "yaml string"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseYAML(strings.NewReader(test.input))
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error = %v, want it to mention %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestLoadRulesYAMLMatchesJSON(t *testing.T) {
+	fromYAML, err := LoadRulesFile("../data.Json/promotions.yaml")
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	fromJSON, err := LoadRulesFile("../data.Json/promotions.json")
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("yaml rules %+v differ from json rules %+v", fromYAML, fromJSON)
+	}
+}
+
+func TestLoadRulesYAMLValidates(t *testing.T) {
+	_, err := LoadRulesYAML(strings.NewReader("- id: BAD\n  type: multi-buy\n  buy: 2\n  pay: 2\n"))
+	if err == nil || !strings.Contains(err.Error(), "buy > pay") {
+		t.Errorf("error = %v, want the multi-buy validation error", err)
+	}
+}
+
+func TestLoadRulesFileYAMLExtension(t *testing.T) {
+	path := t.TempDir() + "/rules.YML"
+	if err := os.WriteFile(path, []byte("- id: P\n  type: percent-off\n  percent: 5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRulesFile(path)
+	if err != nil || len(rules) != 1 || rules[0].Percent != 5 {
+		t.Errorf("got %+v, %v; want one percent-off rule", rules, err)
+	}
+}
@@ -0,0 +1,116 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+
+	"ExamFolder/store"
+)
+
+// aggregate computes one output column of a group.
+type aggregate struct {
+	column Column
+	field  Field
+	group  int
+}
+
+func newAggregate(column Column, groupBy []string, grouped bool) (aggregate, error) {
+	a := aggregate{column: column, group: -1}
+
+	if column.Field == "" {
+		if column.Func != Count {
+			return aggregate{}, fmt.Errorf("query: %s needs a field", column.Func)
+		}
+		return a, nil
+	}
+
+	field, ok := FieldByName(column.Field)
+	if !ok {
+		return aggregate{}, fmt.Errorf("query: unknown field %q", column.Field)
+	}
+	a.field = field
+
+	switch column.Func {
+	case "":
+		if grouped {
+			a.group = slices.Index(groupBy, column.Field)
+			if a.group < 0 {
+				return aggregate{}, fmt.Errorf("query: %s must be grouped by or aggregated", column.Field)
+			}
+		}
+	case Sum, Avg:
+		if field.Kind != Int && field.Kind != Amount {
+			return aggregate{}, fmt.Errorf("query: cannot %s %s values of %s", column.Func, field.Kind, field.Name)
+		}
+	case Count, Min, Max, Distinct:
+	default:
+		return aggregate{}, fmt.Errorf("query: unknown function %q", column.Func)
+	}
+	return a, nil
+}
+
+// apply computes the column over a group. Absent values are skipped; avg,
+// min and max of no values are nil.
+func (a aggregate) apply(g *group) any {
+	if a.column.Func == "" {
+		return g.keys[a.group]
+	}
+	if a.column.Field == "" {
+		return len(g.rows)
+	}
+
+	var values []any
+	for _, row := range g.rows {
+		if value := a.field.Get(row); value != nil {
+			values = append(values, value)
+		}
+	}
+
+	switch a.column.Func {
+	case Count:
+		return len(values)
+	case Distinct:
+		seen := make(map[string]bool)
+		for _, value := range values {
+			seen[fmt.Sprintf("%#v", value)] = true
+		}
+		return len(seen)
+	case Min, Max:
+		if len(values) == 0 {
+			return nil
+		}
+		best := values[0]
+		for _, value := range values[1:] {
+			if n := compare(value, best); n < 0 && a.column.Func == Min || n > 0 && a.column.Func == Max {
+				best = value
+			}
+		}
+		return best
+	}
+
+	if a.field.Kind == Amount {
+		var sum store.Money
+		for _, value := range values {
+			sum = sum.Add(value.(store.Money))
+		}
+		if a.column.Func == Avg {
+			if len(values) == 0 {
+				return nil
+			}
+			return sum.Div(len(values))
+		}
+		return sum
+	}
+
+	sum := 0
+	for _, value := range values {
+		sum += value.(int)
+	}
+	if a.column.Func == Avg {
+		if len(values) == 0 {
+			return nil
+		}
+		return float64(sum) / float64(len(values))
+	}
+	return sum
+}
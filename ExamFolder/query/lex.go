@@ -0,0 +1,103 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenSymbol
+)
+
+// token is one lexeme. Words are identifiers, keywords, numbers, dates and
+// bare literals such as C004; strings were quoted.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// is reports whether t is the keyword or symbol s, ignoring case.
+func (t token) is(s string) bool {
+	return (t.kind == tokenWord || t.kind == tokenSymbol) && strings.EqualFold(t.text, s)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.:-+", r)
+}
+
+// lex splits a query into tokens, ending with tokenEOF.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '\'' || r == '"':
+			start := i
+			var text strings.Builder
+			for i++; ; i++ {
+				if i == len(runes) {
+					return nil, fmt.Errorf("query: unterminated string at %d", start+1)
+				}
+				if runes[i] == r {
+					// A doubled quote stands for the quote itself.
+					if i+1 < len(runes) && runes[i+1] == r {
+						text.WriteRune(r)
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+			}
+			tokens = append(tokens, token{tokenString, text.String(), start})
+
+		case strings.ContainsRune("(),*", r):
+			tokens = append(tokens, token{tokenSymbol, string(r), i})
+			i++
+
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || r == '<' && runes[i] == '>') {
+				i++
+			}
+			op := string(runes[start:i])
+			if op == "!" {
+				return nil, fmt.Errorf("query: unexpected %q at %d", op, start+1)
+			}
+			tokens = append(tokens, token{tokenSymbol, op, start})
+
+		case isWordRune(r) && (r != '-' && r != '+' || i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[start:i]), start})
+
+		default:
+			return nil, fmt.Errorf("query: unexpected %q at %d", r, i+1)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,358 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Expr is a parsed WHERE clause.
+type Expr interface {
+	expr()
+}
+
+// CondExpr is a single comparison.
+type CondExpr struct{ Condition }
+
+// AndExpr holds when both sides hold.
+type AndExpr struct{ Left, Right Expr }
+
+// OrExpr holds when either side holds.
+type OrExpr struct{ Left, Right Expr }
+
+// NotExpr negates its operand.
+type NotExpr struct{ Operand Expr }
+
+func (CondExpr) expr() {}
+func (AndExpr) expr()  {}
+func (OrExpr) expr()   {}
+func (NotExpr) expr()  {}
+
+// Statement is a parsed query, before fields are checked and the WHERE
+// clause is compiled. See Plan.
+type Statement struct {
+	Select  []Column
+	Where   Expr
+	GroupBy []string
+	OrderBy []Order
+	Limit   int
+}
+
+// Parse reads a query in either of two forms. The SQL-like form is
+//
+//	SELECT column [AS name], ... [WHERE condition] [GROUP BY field, ...]
+//	    [ORDER BY column [ASC|DESC], ...] [LIMIT n]
+//
+// where a column is *, a field or func(field) with func one of sum, count,
+// avg, min, max or distinct, and count(*) counts lines. Conditions compare a
+// field with a literal using = != <> < <= > >= or contains, combined with
+// AND, OR, NOT and parentheses. The shorthand form is
+//
+//	measure, ... [BY field, ...] [WHERE condition] [TOP n | BOTTOM n]
+//
+// where a measure is func(field), a field (summed when numeric, else
+// counted distinct), or one of units, lines, customers and baskets. TOP and
+// BOTTOM rank by the first measure. Keywords are case-insensitive.
+func Parse(input string) (Statement, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return Statement{}, err
+	}
+
+	p := &parser{tokens: tokens}
+	var s Statement
+	if p.peek().is("select") {
+		s, err = p.sql()
+	} else {
+		s, err = p.shorthand()
+	}
+	if err != nil {
+		return Statement{}, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return Statement{}, p.errorf(t, "unexpected %s", t)
+	}
+	return s, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the keywords or symbols if they come next.
+func (p *parser) accept(words ...string) bool {
+	for i, word := range words {
+		if p.pos+i >= len(p.tokens) || !p.tokens[p.pos+i].is(word) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *parser) expect(words ...string) error {
+	if !p.accept(words...) {
+		return p.errorf(p.peek(), "expected %s, found %s", strings.ToUpper(strings.Join(words, " ")), p.peek())
+	}
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("query: at %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
+
+var keywords = map[string]bool{
+	"select": true, "as": true, "where": true, "group": true, "order": true, "by": true,
+	"asc": true, "desc": true, "limit": true, "and": true, "or": true, "not": true,
+	"top": true, "bottom": true, "contains": true,
+}
+
+// ident reads a field or alias name.
+func (p *parser) ident(what string) (string, error) {
+	t := p.peek()
+	if t.kind != tokenWord || keywords[strings.ToLower(t.text)] {
+		return "", p.errorf(t, "expected %s, found %s", what, t)
+	}
+	p.next()
+	return strings.ToLower(t.text), nil
+}
+
+func (p *parser) identList(what string) ([]string, error) {
+	var names []string
+	for {
+		name, err := p.ident(what)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.accept(",") {
+			return names, nil
+		}
+	}
+}
+
+func (p *parser) integer() (int, error) {
+	t := p.next()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != tokenWord || err != nil || n < 1 {
+		return 0, p.errorf(t, "expected a positive number, found %s", t)
+	}
+	return n, nil
+}
+
+func (p *parser) sql() (Statement, error) {
+	var s Statement
+	if err := p.expect("select"); err != nil {
+		return s, err
+	}
+
+	if !p.accept("*") {
+		for {
+			column, err := p.column()
+			if err != nil {
+				return s, err
+			}
+			if p.accept("as") {
+				if column.As, err = p.ident("a column name"); err != nil {
+					return s, err
+				}
+			}
+			s.Select = append(s.Select, column)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+
+	var err error
+	if p.accept("where") {
+		if s.Where, err = p.or(); err != nil {
+			return s, err
+		}
+	}
+	if p.accept("group", "by") {
+		if s.GroupBy, err = p.identList("a field"); err != nil {
+			return s, err
+		}
+	}
+	if p.accept("order", "by") {
+		for {
+			column, err := p.column()
+			if err != nil {
+				return s, err
+			}
+			order := Order{Column: column.Name()}
+			if p.accept("desc") {
+				order.Desc = true
+			} else {
+				p.accept("asc")
+			}
+			s.OrderBy = append(s.OrderBy, order)
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	if p.accept("limit") {
+		if s.Limit, err = p.integer(); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// column reads field, func(field) or func(*).
+func (p *parser) column() (Column, error) {
+	name, err := p.ident("a field or function")
+	if err != nil {
+		return Column{}, err
+	}
+	if !p.accept("(") {
+		return Column{Field: name}, nil
+	}
+
+	column := Column{Func: Func(name)}
+	if !p.accept("*") {
+		if column.Field, err = p.ident("a field"); err != nil {
+			return Column{}, err
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return Column{}, err
+	}
+	return column, nil
+}
+
+func (p *parser) or() (Expr, error) {
+	left, err := p.and()
+	for err == nil && p.accept("or") {
+		var right Expr
+		if right, err = p.and(); err == nil {
+			left = OrExpr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (Expr, error) {
+	left, err := p.unary()
+	for err == nil && p.accept("and") {
+		var right Expr
+		if right, err = p.unary(); err == nil {
+			left = AndExpr{left, right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.accept("not") {
+		operand, err := p.unary()
+		return NotExpr{operand}, err
+	}
+	if p.accept("(") {
+		e, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	}
+
+	field, err := p.ident("a field")
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.next()
+	switch {
+	case op.kind == tokenSymbol && op.text != "(" && op.text != ")" && op.text != "," && op.text != "*":
+	case op.is(OpContains):
+	default:
+		return nil, p.errorf(op, "expected a comparison after %s, found %s", field, op)
+	}
+	operator := strings.ToLower(op.text)
+	if operator == "<>" {
+		operator = OpNe
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, p.errorf(value, "expected a value after %s %s, found %s", field, operator, value)
+	}
+	return CondExpr{Condition{Field: field, Op: operator, Value: value.text}}, nil
+}
+
+// measures maps the shorthand words that are not fields to their column.
+var measures = map[string]Column{
+	"units":     {Func: Sum, Field: "quantity", As: "units"},
+	"lines":     {Func: Count, As: "lines"},
+	"customers": {Func: Distinct, Field: "customer_id", As: "customers"},
+	"baskets":   {Func: Distinct, Field: "basket_id", As: "baskets"},
+}
+
+func (p *parser) shorthand() (Statement, error) {
+	var s Statement
+	for {
+		start := p.peek()
+		column, err := p.column()
+		if err != nil {
+			return s, err
+		}
+		if column.Func == "" {
+			if measure, ok := measures[column.Field]; ok {
+				column = measure
+			} else if field, ok := FieldByName(column.Field); !ok {
+				return s, p.errorf(start, "unknown measure %s", start)
+			} else if field.Kind == Int || field.Kind == Amount {
+				column = Column{Func: Sum, Field: field.Name, As: field.Name}
+			} else {
+				column = Column{Func: Distinct, Field: field.Name, As: field.Name}
+			}
+		}
+		s.Select = append(s.Select, column)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	var err error
+	if p.accept("by") {
+		if s.GroupBy, err = p.identList("a field"); err != nil {
+			return s, err
+		}
+		var groups []Column
+		for _, name := range s.GroupBy {
+			groups = append(groups, Column{Field: name})
+		}
+		s.Select = append(groups, s.Select...)
+	}
+	if p.accept("where") {
+		if s.Where, err = p.or(); err != nil {
+			return s, err
+		}
+	}
+
+	measure := s.Select[len(s.GroupBy)].Name()
+	switch {
+	case p.accept("top"):
+		s.OrderBy = []Order{{Column: measure, Desc: true}}
+		s.Limit, err = p.integer()
+	case p.accept("bottom"):
+		s.OrderBy = []Order{{Column: measure}}
+		s.Limit, err = p.integer()
+	}
+	return s, err
+}
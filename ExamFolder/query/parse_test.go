@@ -0,0 +1,245 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex(`cash>=-5 AND name <> 'it''s' (count(*)) "x y"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, token := range tokens {
+		got = append(got, token.text)
+	}
+	want := []string{"cash", ">=", "-5", "AND", "name", "<>", "it's", "(", "count", "(", "*", ")", ")", "x y", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokens = %q, want %q", got, want)
+	}
+	if tokens[6].kind != tokenString || tokens[len(tokens)-1].kind != tokenEOF {
+		t.Errorf("token kinds are wrong: %+v", tokens)
+	}
+
+	for _, input := range []string{`name = 'open`, `a ! b`, `a ; b`, `- 5`} {
+		if _, err := lex(input); err == nil {
+			t.Errorf("lex(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func cond(field, op, value string) Expr {
+	return CondExpr{Condition{Field: field, Op: op, Value: value}}
+}
+
+func TestParseSQL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Statement
+	}{
+		{"select *", Statement{}},
+		{
+			"SELECT category, Sum(Revenue) AS rev WHERE cash > 100 GROUP BY category ORDER BY sum(revenue) DESC, category ASC LIMIT 5",
+			Statement{
+				Select:  []Column{{Field: "category"}, {Func: Sum, Field: "revenue", As: "rev"}},
+				Where:   cond("cash", ">", "100"),
+				GroupBy: []string{"category"},
+				OrderBy: []Order{{Column: "sum(revenue)", Desc: true}, {Column: "category"}},
+				Limit:   5,
+			},
+		},
+		{
+			"select count(*) where name contains 'chi' and quantity <> 2",
+			Statement{
+				Select: []Column{{Func: Count}},
+				Where:  AndExpr{cond("name", "contains", "chi"), cond("quantity", "!=", "2")},
+			},
+		},
+		// AND binds tighter than OR, NOT tighter than AND.
+		{
+			"select name where a = 1 or b = 2 and not c = 3",
+			Statement{
+				Select: []Column{{Field: "name"}},
+				Where:  OrExpr{cond("a", "=", "1"), AndExpr{cond("b", "=", "2"), NotExpr{cond("c", "=", "3")}}},
+			},
+		},
+		{
+			"select name where not a = 1 and b = 2",
+			Statement{
+				Select: []Column{{Field: "name"}},
+				Where:  AndExpr{NotExpr{cond("a", "=", "1")}, cond("b", "=", "2")},
+			},
+		},
+		{
+			"select name where (a = 1 or b = 2) and c = 3 or d = 4",
+			Statement{
+				Select: []Column{{Field: "name"}},
+				Where:  OrExpr{AndExpr{OrExpr{cond("a", "=", "1"), cond("b", "=", "2")}, cond("c", "=", "3")}, cond("d", "=", "4")},
+			},
+		},
+		{
+			"select name where a = 1 or b = 2 or c = 3",
+			Statement{
+				Select: []Column{{Field: "name"}},
+				Where:  OrExpr{OrExpr{cond("a", "=", "1"), cond("b", "=", "2")}, cond("c", "=", "3")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestParseShorthand(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Statement
+	}{
+		{
+			"revenue by category where cash > 100000 top 5",
+			Statement{
+				Select:  []Column{{Field: "category"}, {Func: Sum, Field: "revenue", As: "revenue"}},
+				Where:   cond("cash", ">", "100000"),
+				GroupBy: []string{"category"},
+				OrderBy: []Order{{Column: "revenue", Desc: true}},
+				Limit:   5,
+			},
+		},
+		{
+			"units, customers by month, category bottom 3",
+			Statement{
+				Select: []Column{
+					{Field: "month"}, {Field: "category"},
+					{Func: Sum, Field: "quantity", As: "units"},
+					{Func: Distinct, Field: "customer_id", As: "customers"},
+				},
+				GroupBy: []string{"month", "category"},
+				OrderBy: []Order{{Column: "units"}},
+				Limit:   3,
+			},
+		},
+		{
+			"category, avg(price), lines",
+			Statement{Select: []Column{
+				{Func: Distinct, Field: "category", As: "category"},
+				{Func: Avg, Field: "price"},
+				{Func: Count, As: "lines"},
+			}},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", test.input, got, test.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "expected a field or function"},
+		{"select", "expected a field or function"},
+		{"select name where", "expected a field"},
+		{"select name where price 5", "expected a comparison"},
+		{"select name where price =", "expected a value"},
+		{"select name where (price = 5", "expected )"},
+		{"select name limit 0", "positive number"},
+		{"select name limit x", "positive number"},
+		{"select sum(price", "expected )"},
+		{"select name as select", "expected a column name"},
+		{"select name extra", `unexpected "extra"`},
+		{"select name group category", `unexpected "group"`},
+		{"colour by category", "unknown measure"},
+		{"revenue by", "expected a field"},
+		{"revenue top", "positive number"},
+		{"revenue where name = 'x", "unterminated string"},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.input)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Parse(%q) error = %v, want %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	s, err := Parse("select category, sum(revenue) as rev group by category order by sum(revenue) desc, category")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := s.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Order{{Column: "rev", Desc: true}, {Column: "category"}}; !reflect.DeepEqual(q.OrderBy, want) {
+		t.Errorf("OrderBy = %+v, want the alias rev", q.OrderBy)
+	}
+
+	for _, input := range []string{
+		"select name group by colour",
+		"select name where colour = 'red'",
+		"select name where not (quantity = many)",
+		"select name where name = 'x' or price > cheap",
+	} {
+		if _, err := Compile(input); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestCompileAndRun(t *testing.T) {
+	tests := []struct {
+		input string
+		want  [][]any
+	}{
+		{"revenue by category top 2", [][]any{{"Tech", store.Major(50, "")}, {"Snack", store.Major(8, "")}}},
+		{"units by customer_id bottom 1", [][]any{{"C2", 3}}},
+		{
+			"select customer_id, count(*) as n where not category = 'Tech' group by customer_id order by count(*) desc",
+			[][]any{{"C2", 2}, {"C1", 1}},
+		},
+		{
+			"SELECT product_id, quantity WHERE category = Snack OR price >= 50 AND quantity < 2 ORDER BY quantity",
+			[][]any{{"P2", 1}, {"P1", 1}, {"P1", 3}},
+		},
+		{"lines, customers, baskets where name contains 'E'", [][]any{{2, 2, 2}}},
+	}
+
+	for _, test := range tests {
+		q, err := Compile(test.input)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", test.input, err)
+			continue
+		}
+		result, err := q.Run(testCustomers())
+		if err != nil {
+			t.Errorf("Run(%q): %v", test.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(result.Rows, test.want) {
+			t.Errorf("%q: rows = %v, want %v", test.input, result.Rows, test.want)
+		}
+	}
+}
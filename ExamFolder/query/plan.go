@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+)
+
+// Plan checks the statement against the known fields and turns it into an
+// executable Query. ORDER BY may name a column by its alias or by the
+// expression it aliases.
+func (s Statement) Plan() (Query, error) {
+	q := Query{Select: s.Select, GroupBy: s.GroupBy, Limit: s.Limit}
+
+	for _, name := range s.GroupBy {
+		if _, ok := FieldByName(name); !ok {
+			return Query{}, fmt.Errorf("query: unknown field %q in GROUP BY", name)
+		}
+	}
+
+	if s.Where != nil {
+		where, err := compileExpr(s.Where)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Where = where
+	}
+
+	for _, order := range s.OrderBy {
+		for _, column := range s.Select {
+			unaliased := column
+			unaliased.As = ""
+			if column.As != "" && unaliased.Name() == order.Column {
+				order.Column = column.As
+			}
+		}
+		q.OrderBy = append(q.OrderBy, order)
+	}
+
+	return q, nil
+}
+
+func compileExpr(e Expr) (Predicate, error) {
+	switch e := e.(type) {
+	case CondExpr:
+		return e.Compile()
+	case NotExpr:
+		operand, err := compileExpr(e.Operand)
+		if err != nil {
+			return nil, err
+		}
+		return Not(operand), nil
+	case AndExpr:
+		left, right, err := compilePair(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return And(left, right), nil
+	case OrExpr:
+		left, right, err := compilePair(e.Left, e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return Or(left, right), nil
+	default:
+		return nil, fmt.Errorf("query: unknown expression %T", e)
+	}
+}
+
+func compilePair(left, right Expr) (Predicate, Predicate, error) {
+	l, err := compileExpr(left)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := compileExpr(right)
+	if err != nil {
+		return nil, nil, err
+	}
+	return l, r, nil
+}
+
+// Compile parses and plans a query; run the result with Query.Run.
+func Compile(input string) (Query, error) {
+	s, err := Parse(input)
+	if err != nil {
+		return Query{}, err
+	}
+	return s.Plan()
+}
@@ -0,0 +1,328 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ExamFolder/store"
+)
+
+// Predicate selects rows.
+type Predicate func(Row) bool
+
+// And holds when every predicate holds; with none it always holds.
+func And(predicates ...Predicate) Predicate {
+	return func(r Row) bool {
+		for _, p := range predicates {
+			if !p(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or holds when any predicate holds; with none it never holds.
+func Or(predicates ...Predicate) Predicate {
+	return func(r Row) bool {
+		for _, p := range predicates {
+			if p(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not negates a predicate.
+func Not(p Predicate) Predicate {
+	return func(r Row) bool { return !p(r) }
+}
+
+// Operators understood by Condition.
+const (
+	OpEq       = "="
+	OpNe       = "!="
+	OpLt       = "<"
+	OpLe       = "<="
+	OpGt       = ">"
+	OpGe       = ">="
+	OpContains = "contains"
+)
+
+// Condition compares a field with a literal, e.g. {"cash", ">", "100000"}.
+// The literal is read as the field's kind. Absent values only match !=.
+// contains is a case-insensitive substring match on the value's text.
+type Condition struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// Compile checks the condition and turns it into a Predicate.
+func (c Condition) Compile() (Predicate, error) {
+	field, ok := FieldByName(c.Field)
+	if !ok {
+		return nil, fmt.Errorf("query: unknown field %q", c.Field)
+	}
+
+	if c.Op == OpContains {
+		needle := strings.ToLower(c.Value)
+		return func(r Row) bool {
+			value := field.Get(r)
+			return value != nil && strings.Contains(strings.ToLower(fmt.Sprint(value)), needle)
+		}, nil
+	}
+
+	literal, err := parseValue(field.Kind, c.Value)
+	if err != nil {
+		return nil, fmt.Errorf("query: %s %s %q: %w", c.Field, c.Op, c.Value, err)
+	}
+
+	var test func(int) bool
+	switch c.Op {
+	case OpEq:
+		test = func(n int) bool { return n == 0 }
+	case OpNe:
+		test = func(n int) bool { return n != 0 }
+	case OpLt:
+		test = func(n int) bool { return n < 0 }
+	case OpLe:
+		test = func(n int) bool { return n <= 0 }
+	case OpGt:
+		test = func(n int) bool { return n > 0 }
+	case OpGe:
+		test = func(n int) bool { return n >= 0 }
+	default:
+		return nil, fmt.Errorf("query: unknown operator %q", c.Op)
+	}
+
+	return func(r Row) bool {
+		value := field.Get(r)
+		if value == nil {
+			return c.Op == OpNe
+		}
+		return test(compare(value, literal))
+	}, nil
+}
+
+// Func is an aggregate function. The zero Func selects the field itself.
+type Func string
+
+const (
+	Sum      Func = "sum"
+	Count    Func = "count"
+	Avg      Func = "avg"
+	Min      Func = "min"
+	Max      Func = "max"
+	Distinct Func = "distinct"
+)
+
+// Column is one output column: a field, or an aggregate over a field.
+// Count may leave Field empty to count rows.
+type Column struct {
+	Func  Func
+	Field string
+	As    string
+}
+
+// Name is the column heading: As when set, else e.g. "category",
+// "sum(revenue)" or "count(*)".
+func (c Column) Name() string {
+	switch {
+	case c.As != "":
+		return c.As
+	case c.Func == "":
+		return c.Field
+	case c.Field == "":
+		return string(c.Func) + "(*)"
+	default:
+		return string(c.Func) + "(" + c.Field + ")"
+	}
+}
+
+// Order sorts the result by one of its columns.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// Query describes a question over the rows. When GroupBy is set or any
+// column aggregates, rows are grouped (all into one group without GroupBy)
+// and plain columns must be grouped fields; otherwise every matching row is
+// returned. An empty Select means the grouped fields, or every field.
+// Groups come out ordered by their fields, rows in input order; OrderBy
+// then sorts on top of that and Limit, when positive, keeps the first rows.
+type Query struct {
+	Select  []Column
+	Where   Predicate
+	GroupBy []string
+	OrderBy []Order
+	Limit   int
+}
+
+// Result is a table of values: string, int, float64, store.Money,
+// time.Time or nil.
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Run executes the query over the customers' basket lines.
+func (q Query) Run(customers []store.Customer) (Result, error) {
+	return q.RunRows(Rows(customers))
+}
+
+// RunRows executes the query over rows that are already flattened.
+func (q Query) RunRows(rows []Row) (Result, error) {
+	grouped := len(q.GroupBy) > 0
+	for _, column := range q.Select {
+		grouped = grouped || column.Func != ""
+	}
+
+	columns := q.Select
+	if len(columns) == 0 {
+		if grouped {
+			for _, name := range q.GroupBy {
+				columns = append(columns, Column{Field: name})
+			}
+		} else {
+			for _, field := range Fields {
+				columns = append(columns, Column{Field: field.Name})
+			}
+		}
+	}
+
+	groupFields, err := fields(q.GroupBy)
+	if err != nil {
+		return Result{}, err
+	}
+	aggregates := make([]aggregate, len(columns))
+	for i, column := range columns {
+		if aggregates[i], err = newAggregate(column, q.GroupBy, grouped); err != nil {
+			return Result{}, err
+		}
+	}
+
+	var matching []Row
+	for _, row := range rows {
+		if q.Where == nil || q.Where(row) {
+			matching = append(matching, row)
+		}
+	}
+
+	result := Result{}
+	for _, column := range columns {
+		result.Columns = append(result.Columns, column.Name())
+	}
+
+	if !grouped {
+		for _, row := range matching {
+			values := make([]any, len(columns))
+			for i, a := range aggregates {
+				values[i] = a.field.Get(row)
+			}
+			result.Rows = append(result.Rows, values)
+		}
+	} else {
+		result.Rows = groupRows(matching, groupFields, columns, aggregates)
+	}
+
+	if err := sortRows(result, q.OrderBy); err != nil {
+		return Result{}, err
+	}
+	if q.Limit > 0 && q.Limit < len(result.Rows) {
+		result.Rows = result.Rows[:q.Limit]
+	}
+	return result, nil
+}
+
+func fields(names []string) ([]Field, error) {
+	result := make([]Field, len(names))
+	for i, name := range names {
+		field, ok := FieldByName(name)
+		if !ok {
+			return nil, fmt.Errorf("query: unknown field %q", name)
+		}
+		result[i] = field
+	}
+	return result, nil
+}
+
+// group is the rows sharing the same group-by values.
+type group struct {
+	keys []any
+	rows []Row
+}
+
+func groupRows(rows []Row, groupFields []Field, columns []Column, aggregates []aggregate) [][]any {
+	var groups []*group
+	index := make(map[string]*group)
+
+	for _, row := range rows {
+		keys := make([]any, len(groupFields))
+		for i, field := range groupFields {
+			keys[i] = field.Get(row)
+		}
+		id := fmt.Sprintf("%#v", keys)
+
+		g, ok := index[id]
+		if !ok {
+			g = &group{keys: keys}
+			index[id] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, row)
+	}
+
+	// One group even without rows, so totals come out as zero.
+	if len(groupFields) == 0 && len(groups) == 0 {
+		groups = append(groups, &group{})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		for k := range groupFields {
+			if n := compare(groups[i].keys[k], groups[j].keys[k]); n != 0 {
+				return n < 0
+			}
+		}
+		return false
+	})
+
+	result := make([][]any, len(groups))
+	for i, g := range groups {
+		values := make([]any, len(columns))
+		for j, a := range aggregates {
+			values[j] = a.apply(g)
+		}
+		result[i] = values
+	}
+	return result
+}
+
+func sortRows(result Result, orders []Order) error {
+	indexes := make([]int, len(orders))
+	for i, order := range orders {
+		indexes[i] = -1
+		for j, column := range result.Columns {
+			if column == order.Column {
+				indexes[i] = j
+			}
+		}
+		if indexes[i] < 0 {
+			return fmt.Errorf("query: cannot order by %q: not a selected column", order.Column)
+		}
+	}
+
+	sort.SliceStable(result.Rows, func(a, b int) bool {
+		for i, order := range orders {
+			n := compare(result.Rows[a][indexes[i]], result.Rows[b][indexes[i]])
+			if n != 0 {
+				return (n < 0) != order.Desc
+			}
+		}
+		return false
+	})
+	return nil
+}
@@ -0,0 +1,207 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"ExamFolder/store"
+)
+
+func testCustomers() []store.Customer {
+	line := func(id, category, name string, price int64, quantity int) store.Product {
+		return store.Product{ID: id, Category: category, Name: name, Price: store.Major(price, ""), Quantity: quantity}
+	}
+	basket := func(id string, purchasedAt *time.Time, products ...store.Product) store.Basket {
+		b := store.Basket{ID: id, PurchasedAt: purchasedAt, Products: products}
+		b.Total = b.ProductsTotal()
+		return b
+	}
+	march := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	b1 := basket("B1", &march, line("P1", "Snack", "Chips", 2, 3), line("P2", "Tech", "Mouse", 50, 1))
+	b2 := basket("B2", nil, line("P1", "Snack", "Chips", 2, 1), line("P3", "Bakery", "Bread", 3, 2))
+	return []store.Customer{
+		{ID: "C1", FirstName: "Ann", Cash: store.Major(200, ""), Basket: b1, Orders: []store.Basket{b1}},
+		{ID: "C2", FirstName: "Bo", Cash: store.Major(50, ""), Basket: b2, Orders: []store.Basket{b2}},
+		{ID: "C3", FirstName: "Cy", Cash: store.Major(10, "")},
+	}
+}
+
+func TestRows(t *testing.T) {
+	rows := Rows(testCustomers())
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want 4: customers without lines add none", len(rows))
+	}
+	if rows[3].Customer.ID != "C2" || rows[3].Basket.ID != "B2" || rows[3].Product.ID != "P3" {
+		t.Errorf("last row = %+v, want C2/B2/P3", rows[3])
+	}
+}
+
+func TestCondition(t *testing.T) {
+	rows := Rows(testCustomers())
+	tests := []struct {
+		condition Condition
+		want      []string // product IDs of the matching rows
+	}{
+		{Condition{"cash", OpGt, "100"}, []string{"P1", "P2"}},
+		{Condition{"cash", OpLe, "50"}, []string{"P1", "P3"}},
+		{Condition{"quantity", OpGe, "2"}, []string{"P1", "P3"}},
+		{Condition{"category", OpEq, "Snack"}, []string{"P1", "P1"}},
+		{Condition{"category", OpNe, "Snack"}, []string{"P2", "P3"}},
+		{Condition{"price", OpLt, "2.5"}, []string{"P1", "P1"}},
+		{Condition{"name", OpContains, "HI"}, []string{"P1", "P1"}},
+		{Condition{"purchased_at", OpEq, "2024-03-01T10:00:00Z"}, []string{"P1", "P2"}},
+		{Condition{"purchased_at", OpGe, "2024-03-01"}, []string{"P1", "P2"}},
+		// Undated orders only match !=.
+		{Condition{"purchased_at", OpNe, "2024-03-01T10:00:00Z"}, []string{"P1", "P3"}},
+		{Condition{"purchased_at", OpLt, "2030-01-01"}, []string{"P1", "P2"}},
+	}
+
+	for _, test := range tests {
+		predicate, err := test.condition.Compile()
+		if err != nil {
+			t.Errorf("%v: %v", test.condition, err)
+			continue
+		}
+		var got []string
+		for _, row := range rows {
+			if predicate(row) {
+				got = append(got, row.Product.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v matched %v, want %v", test.condition, got, test.want)
+		}
+	}
+
+	for _, condition := range []Condition{
+		{"colour", OpEq, "red"},
+		{"quantity", OpEq, "many"},
+		{"cash", OpGt, "1.001"},
+		{"purchased_at", OpGt, "March"},
+		{"category", "~", "Snack"},
+	} {
+		if _, err := condition.Compile(); err == nil {
+			t.Errorf("%v compiled, want an error", condition)
+		}
+	}
+}
+
+func TestPredicateCombinators(t *testing.T) {
+	yes := func(Row) bool { return true }
+	no := func(Row) bool { return false }
+	row := Row{}
+
+	if !And()(row) || Or()(row) {
+		t.Error("And() should hold and Or() should not")
+	}
+	if And(yes, no)(row) || !Or(no, yes)(row) || Not(yes)(row) {
+		t.Error("And/Or/Not gave the wrong answer")
+	}
+}
+
+func TestRunGroupBy(t *testing.T) {
+	q := Query{
+		Select: []Column{
+			{Field: "category"},
+			{Func: Sum, Field: "revenue"},
+			{Func: Count},
+			{Func: Avg, Field: "quantity"},
+			{Func: Min, Field: "price"},
+			{Func: Max, Field: "name"},
+			{Func: Distinct, Field: "customer_id", As: "buyers"},
+		},
+		GroupBy: []string{"category"},
+	}
+	result, err := q.Run(testCustomers())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantColumns := []string{"category", "sum(revenue)", "count(*)", "avg(quantity)", "min(price)", "max(name)", "buyers"}
+	if !reflect.DeepEqual(result.Columns, wantColumns) {
+		t.Errorf("columns = %v, want %v", result.Columns, wantColumns)
+	}
+	want := [][]any{
+		{"Bakery", store.Major(6, ""), 1, 2.0, store.Major(3, ""), "Bread", 1},
+		{"Snack", store.Major(8, ""), 2, 2.0, store.Major(2, ""), "Chips", 2},
+		{"Tech", store.Major(50, ""), 1, 1.0, store.Major(50, ""), "Mouse", 1},
+	}
+	if !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows = %v, want %v", result.Rows, want)
+	}
+}
+
+func TestRunOrderAndLimit(t *testing.T) {
+	q := Query{
+		Select:  []Column{{Field: "customer_id"}, {Func: Sum, Field: "quantity", As: "units"}},
+		GroupBy: []string{"customer_id"},
+		OrderBy: []Order{{Column: "units", Desc: true}},
+		Limit:   1,
+	}
+	result, err := q.Run(testCustomers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]any{{"C1", 4}}; !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows = %v, want %v", result.Rows, want)
+	}
+}
+
+func TestRunTotalsOfNoRows(t *testing.T) {
+	none, _ := Condition{"category", OpEq, "Toys"}.Compile()
+	q := Query{
+		Select: []Column{{Func: Sum, Field: "revenue"}, {Func: Sum, Field: "quantity"}, {Func: Count}, {Func: Avg, Field: "price"}, {Func: Max, Field: "price"}},
+		Where:  none,
+	}
+	result, err := q.Run(testCustomers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]any{{store.Money{}, 0, 0, nil, nil}}; !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows = %v, want one row of zero totals", result.Rows)
+	}
+}
+
+func TestRunUngrouped(t *testing.T) {
+	result, err := Query{Limit: 2}.Run(testCustomers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Columns) != len(Fields) || len(result.Rows) != 2 {
+		t.Fatalf("got %d columns and %d rows, want every field and 2 rows", len(result.Columns), len(result.Rows))
+	}
+
+	where, _ := Condition{"purchased_at", OpNe, "2024-03-01T10:00:00Z"}.Compile()
+	result, err = Query{Select: []Column{{Field: "product_id"}, {Field: "purchased_at"}}, Where: where}.Run(testCustomers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]any{{"P1", nil}, {"P3", nil}}; !reflect.DeepEqual(result.Rows, want) {
+		t.Errorf("rows = %v, want the undated lines with nil purchased_at", result.Rows)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{"ungrouped plain column", Query{Select: []Column{{Field: "name"}, {Func: Count}}}, "must be grouped by or aggregated"},
+		{"sum of text", Query{Select: []Column{{Func: Sum, Field: "name"}}}, "cannot sum string"},
+		{"avg without field", Query{Select: []Column{{Func: Avg}}}, "avg needs a field"},
+		{"unknown function", Query{Select: []Column{{Func: "median", Field: "price"}}}, "unknown function"},
+		{"unknown field", Query{Select: []Column{{Field: "colour"}}}, "unknown field"},
+		{"unknown group", Query{GroupBy: []string{"colour"}}, "unknown field"},
+		{"order by unselected", Query{Select: []Column{{Field: "name"}}, OrderBy: []Order{{Column: "price"}}}, "not a selected column"},
+	}
+	for _, test := range tests {
+		_, err := test.query.Run(testCustomers())
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: error = %v, want %q", test.name, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// Row is one basket line with the order and customer it belongs to.
+type Row struct {
+	Customer store.Customer
+	Basket   store.Basket
+	Product  store.Product
+}
+
+// Rows flattens every line of every order. Customers and orders without
+// lines produce no rows.
+func Rows(customers []store.Customer) []Row {
+	var rows []Row
+	for _, customer := range customers {
+		for _, basket := range customer.History() {
+			for _, product := range basket.Products {
+				rows = append(rows, Row{Customer: customer, Basket: basket, Product: product})
+			}
+		}
+	}
+	return rows
+}
+
+// Kind is the type of a field's values.
+type Kind string
+
+const (
+	String Kind = "string"
+	Int    Kind = "int"
+	Amount Kind = "money"
+	Time   Kind = "time"
+)
+
+// Field is a column of Row. Get returns a string, int, store.Money or
+// time.Time matching Kind, or nil when the value is absent, as
+// purchased_at is for undated orders.
+type Field struct {
+	Name string
+	Kind Kind
+	Get  func(Row) any
+}
+
+// period returns a field bucketing purchased_at by g.
+func period(name string, g task.Granularity) Field {
+	return Field{name, String, func(r Row) any {
+		if r.Basket.PurchasedAt == nil {
+			return nil
+		}
+		return g.Label(g.Start(*r.Basket.PurchasedAt))
+	}}
+}
+
+// Fields lists every field a query can filter, group or aggregate on.
+var Fields = []Field{
+	{"customer_id", String, func(r Row) any { return r.Customer.ID }},
+	{"first_name", String, func(r Row) any { return r.Customer.FirstName }},
+	{"last_name", String, func(r Row) any { return r.Customer.LastName }},
+	{"cash", Amount, func(r Row) any { return r.Customer.Cash }},
+	{"basket_id", String, func(r Row) any { return r.Basket.ID }},
+	{"basket_total", Amount, func(r Row) any { return r.Basket.Total }},
+	{"purchased_at", Time, func(r Row) any {
+		if r.Basket.PurchasedAt == nil {
+			return nil
+		}
+		return *r.Basket.PurchasedAt
+	}},
+	period("day", task.Day),
+	period("week", task.Week),
+	period("month", task.Month),
+	{"product_id", String, func(r Row) any { return r.Product.ID }},
+	{"category", String, func(r Row) any { return r.Product.Category }},
+	{"name", String, func(r Row) any { return r.Product.Name }},
+	{"price", Amount, func(r Row) any { return r.Product.Price }},
+	{"quantity", Int, func(r Row) any { return r.Product.Quantity }},
+	{"revenue", Amount, func(r Row) any { return r.Product.LineTotal() }},
+}
+
+// FieldByName finds a field.
+func FieldByName(name string) (Field, bool) {
+	for _, f := range Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ExamFolder/store"
+)
+
+// compare orders two values of the same kind. nil sorts before everything;
+// values of different types compare by their text.
+func compare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	case int:
+		if y, ok := b.(int); ok {
+			return cmpOrdered(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmpOrdered(x, y)
+		}
+	case store.Money:
+		if y, ok := b.(store.Money); ok {
+			return x.Cmp(y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func cmpOrdered[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseValue reads a literal as a value of kind, for comparing with a field.
+func parseValue(kind Kind, literal string) (any, error) {
+	switch kind {
+	case Int:
+		return strconv.Atoi(literal)
+	case Amount:
+		return store.ParseMoney(literal)
+	case Time:
+		if t, err := time.Parse(time.DateOnly, literal); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, literal)
+	default:
+		return literal, nil
+	}
+}
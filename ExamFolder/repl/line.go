@@ -0,0 +1,57 @@
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// lineReader reads one command line at a time.
+type lineReader interface {
+	// ReadLine shows prompt and returns the line without its newline, or
+	// io.EOF at the end of input. history lists earlier lines, oldest first.
+	ReadLine(prompt string, history []string) (string, error)
+	Close() error
+}
+
+// plainReader is the fallback used when in is not a terminal or the
+// platform has no line editor: no editing, history or completion.
+type plainReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func newPlainReader(in *os.File, out io.Writer) *plainReader {
+	return &plainReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+func (r *plainReader) ReadLine(prompt string, history []string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+func (r *plainReader) Close() error {
+	return nil
+}
+
+// commonPrefix returns the longest prefix shared by every word.
+func commonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,461 @@
+package repl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ExamFolder/query"
+	"ExamFolder/report"
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// Shell keeps a dataset in memory and answers commands about it.
+type Shell struct {
+	data      string
+	customers []store.Customer
+	history   []string
+	out       io.Writer
+}
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(s *Shell, args []string) error
+	// raw commands get the rest of the line untouched as their only
+	// argument, so quoted text keeps its spacing.
+	raw bool
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"customers", "customers", "list customers", (*Shell).customersCommand, false},
+		{"show", "show CUSTOMER_ID", "show a customer and their basket", (*Shell).showCommand, false},
+		{"basket", "basket CUSTOMER_ID", "show a customer's basket lines", (*Shell).basketCommand, false},
+		{"orders", "orders CUSTOMER_ID", "show a customer's orders", (*Shell).ordersCommand, false},
+		{"task", "task N|NAME...", "print tasks as console prose", (*Shell).taskCommand, false},
+		{"top", "top products|customers [N]", "rank products by revenue or customers by spending", (*Shell).topCommand, false},
+		{"filter", "filter FIELD=VALUE...", "list basket lines matching every condition (= != < <= > >=)", (*Shell).filterCommand, false},
+		{"query", "query QUERY", "run a query, e.g. query revenue by category top 5", (*Shell).queryCommand, true},
+		{"reload", "reload", "read the dataset again", (*Shell).reloadCommand, false},
+		{"history", "history", "list the commands entered so far", (*Shell).historyCommand, false},
+		{"help", "help", "show this help", (*Shell).helpCommand, false},
+		{"exit", "exit", "leave the shell (also quit or Ctrl-D)", nil, false},
+	}
+}
+
+// errExit ends Run.
+var errExit = errors.New("exit")
+
+// New loads the dataset once with store.ReadData.
+func New(data string, out io.Writer) (*Shell, error) {
+	s := &Shell{data: data, out: out}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Shell) load() error {
+	customers, err := store.ReadData(s.data)
+	if err != nil {
+		return err
+	}
+	s.customers = customers
+	return nil
+}
+
+// Run reads commands from in until exit or end of input. On Linux a
+// terminal on in gets line editing with history and tab completion; other
+// systems read plain lines.
+func (s *Shell) Run(in *os.File) error {
+	reader := newLineReader(in, s.out, s.Complete)
+	defer reader.Close()
+
+	fmt.Fprintf(s.out, "Loaded %d customers from %s. Type help for commands.\n", len(s.customers), s.data)
+	for {
+		line, err := reader.ReadLine("exam> ", s.history)
+		if err == io.EOF {
+			fmt.Fprintln(s.out)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s.history = append(s.history, line)
+
+		if err := s.Execute(line); err == errExit {
+			return nil
+		} else if err != nil {
+			fmt.Fprintf(s.out, "error: %v\n", err)
+		}
+	}
+}
+
+// Execute runs one command line.
+func (s *Shell) Execute(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	name := strings.ToLower(fields[0])
+	if name == "quit" {
+		name = "exit"
+	}
+	for _, c := range commands {
+		if c.name == name {
+			if c.run == nil {
+				return errExit
+			}
+			if c.raw {
+				return c.run(s, rawArgs(line))
+			}
+			return c.run(s, fields[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %q, type help for commands", fields[0])
+}
+
+// rawArgs returns what follows the command word of line, trimmed, as a
+// single argument, or no arguments when nothing does.
+func rawArgs(line string) []string {
+	line = strings.TrimLeft(line, " \t")
+	rest := strings.TrimSpace(line[len(strings.Fields(line)[0]):])
+	if rest == "" {
+		return nil
+	}
+	return []string{rest}
+}
+
+func (s *Shell) render(r report.Report) error {
+	return report.Render(s.out, r, report.FormatText)
+}
+
+func (s *Shell) findCustomer(id string) (store.Customer, error) {
+	for _, customer := range s.customers {
+		if strings.EqualFold(customer.ID, id) {
+			return customer, nil
+		}
+	}
+	return store.Customer{}, fmt.Errorf("customer %s not found", id)
+}
+
+func oneArg(args []string, usage string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("usage: %s", usage)
+	}
+	return args[0], nil
+}
+
+func (s *Shell) customersCommand(args []string) error {
+	var r report.Report
+	t := r.Add("customers", "Customers", "customer_id", "first_name", "last_name", "cash", "orders", "basket_total")
+	for _, c := range s.customers {
+		t.AddRow(c.ID, c.FirstName, c.LastName, c.Cash, len(c.History()), c.Basket.Total)
+	}
+	return s.render(r)
+}
+
+func (s *Shell) showCommand(args []string) error {
+	id, err := oneArg(args, "show CUSTOMER_ID")
+	if err != nil {
+		return err
+	}
+	customer, err := s.findCustomer(id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(s.out, "%s %s %s\n", customer.ID, customer.FirstName, customer.LastName)
+	fmt.Fprintf(s.out, "Cash:    %s\n", customer.Cash)
+	fmt.Fprintf(s.out, "Orders:  %d\n", len(customer.History()))
+	fmt.Fprintf(s.out, "Spent:   %s\n", customer.Basket.Total)
+	return s.basketCommand(args)
+}
+
+func (s *Shell) basketCommand(args []string) error {
+	id, err := oneArg(args, "basket CUSTOMER_ID")
+	if err != nil {
+		return err
+	}
+	customer, err := s.findCustomer(id)
+	if err != nil {
+		return err
+	}
+
+	var r report.Report
+	t := r.Add("basket", "Basket "+customer.Basket.ID, "product_id", "category", "name", "price", "quantity", "line_total")
+	for _, p := range customer.Basket.Products {
+		t.AddRow(p.ID, p.Category, p.Name, p.Price, p.Quantity, p.LineTotal())
+	}
+	t.AddRow("Total", "", "", nil, nil, customer.Basket.Total)
+	return s.render(r)
+}
+
+func (s *Shell) ordersCommand(args []string) error {
+	id, err := oneArg(args, "orders CUSTOMER_ID")
+	if err != nil {
+		return err
+	}
+	customer, err := s.findCustomer(id)
+	if err != nil {
+		return err
+	}
+
+	var r report.Report
+	t := r.Add("orders", "Orders of "+customer.ID, "basket_id", "purchased_at", "product_lines", "total")
+	for _, order := range customer.History() {
+		var purchasedAt any
+		if order.PurchasedAt != nil {
+			purchasedAt = *order.PurchasedAt
+		}
+		t.AddRow(order.ID, purchasedAt, len(order.Products), order.Total)
+	}
+	return s.render(r)
+}
+
+func (s *Shell) taskCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: task N|NAME...")
+	}
+
+	var names []string
+	for _, arg := range args {
+		if number, err := strconv.Atoi(arg); err == nil {
+			info, ok := task.TaskInfo(number)
+			if !ok {
+				return fmt.Errorf("unknown task %d", number)
+			}
+			arg = info.Name
+		}
+		names = append(names, arg)
+	}
+	return task.PrintAnalyses(s.out, s.customers, names)
+}
+
+func (s *Shell) topCommand(args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return fmt.Errorf("usage: top products|customers [N]")
+	}
+	n := 5
+	if len(args) == 2 {
+		var err error
+		if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+			return fmt.Errorf("top: %q is not a positive number", args[1])
+		}
+	}
+
+	var r report.Report
+	switch args[0] {
+	case "products":
+		t := r.Add("top-products", fmt.Sprintf("Top %d products by revenue", n), "rank", "product_id", "name", "category", "units", "revenue")
+		for i, p := range task.TopProductsByRevenue(s.customers, n) {
+			t.AddRow(i+1, p.ID, p.Name, p.Category, p.Units, p.Revenue)
+		}
+	case "customers":
+		t := r.Add("top-customers", fmt.Sprintf("Top %d customers by spending", n), "rank", "customer_id", "first_name", "last_name", "spent")
+		for i, c := range task.TopCustomers(s.customers, n) {
+			t.AddRow(i+1, c.ID, c.FirstName, c.LastName, c.Basket.Total)
+		}
+	default:
+		return fmt.Errorf("top: expected products or customers, got %q", args[0])
+	}
+	return s.render(r)
+}
+
+// filterOps is checked in order so two-character operators win.
+var filterOps = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+func parseCondition(arg string) (query.Condition, error) {
+	for _, op := range filterOps {
+		if field, value, ok := strings.Cut(arg, op); ok && field != "" {
+			return query.Condition{Field: strings.ToLower(field), Op: op, Value: value}, nil
+		}
+	}
+	return query.Condition{}, fmt.Errorf("filter: %q is not FIELD=VALUE", arg)
+}
+
+func (s *Shell) filterCommand(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: filter FIELD=VALUE...")
+	}
+
+	var predicates []query.Predicate
+	for _, arg := range args {
+		condition, err := parseCondition(arg)
+		if err != nil {
+			return err
+		}
+		predicate, err := condition.Compile()
+		if err != nil {
+			return err
+		}
+		predicates = append(predicates, predicate)
+	}
+
+	q := query.Query{
+		Select: []query.Column{
+			{Field: "customer_id"}, {Field: "basket_id"}, {Field: "product_id"},
+			{Field: "category"}, {Field: "name"}, {Field: "price"}, {Field: "quantity"}, {Field: "revenue"},
+		},
+		Where: query.And(predicates...),
+	}
+	return s.runQuery(q, strings.Join(args, " "))
+}
+
+func (s *Shell) queryCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: query QUERY")
+	}
+	text := args[0]
+	q, err := query.Compile(text)
+	if err != nil {
+		return err
+	}
+	return s.runQuery(q, text)
+}
+
+func (s *Shell) runQuery(q query.Query, title string) error {
+	result, err := q.Run(s.customers)
+	if err != nil {
+		return err
+	}
+
+	var r report.Report
+	t := r.Add("query", fmt.Sprintf("%s (%d rows)", title, len(result.Rows)), result.Columns...)
+	for _, row := range result.Rows {
+		t.AddRow(row...)
+	}
+	return s.render(r)
+}
+
+func (s *Shell) reloadCommand(args []string) error {
+	if err := s.load(); err != nil {
+		return err
+	}
+	fmt.Fprintf(s.out, "Reloaded %d customers from %s.\n", len(s.customers), s.data)
+	return nil
+}
+
+func (s *Shell) historyCommand(args []string) error {
+	for i, line := range s.history {
+		fmt.Fprintf(s.out, "%4d  %s\n", i+1, line)
+	}
+	return nil
+}
+
+func (s *Shell) helpCommand(args []string) error {
+	fmt.Fprintln(s.out, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-28s %s\n", c.usage, c.summary)
+	}
+	if !lineEditing {
+		fmt.Fprintln(s.out, "Line editing, arrow-key history and tab completion are only available on Linux.")
+	}
+	return nil
+}
+
+// Complete returns the words that can replace the last word of line: command
+// names first, then customer IDs, task names, categories, product IDs and
+// field names depending on the command.
+func (s *Shell) Complete(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.HasSuffix(line, " ") && len(fields) == 1 {
+		var names []string
+		for _, c := range commands {
+			names = append(names, c.name)
+		}
+		return matching(names, lastWord(line))
+	}
+
+	word := lastWord(line)
+	switch strings.ToLower(fields[0]) {
+	case "show", "basket", "orders":
+		return matching(s.customerIDs(), word)
+	case "task":
+		var names []string
+		for _, info := range task.Analyses() {
+			names = append(names, info.Name)
+		}
+		return matching(names, word)
+	case "top":
+		return matching([]string{"products", "customers"}, word)
+	case "filter":
+		field, value, ok := strings.Cut(word, "=")
+		if !ok {
+			var names []string
+			for _, f := range query.Fields {
+				names = append(names, f.Name+"=")
+			}
+			return matching(names, word)
+		}
+		var values []string
+		for _, v := range s.fieldValues(field) {
+			values = append(values, field+"="+v)
+		}
+		return matching(values, field+"="+value)
+	}
+	return nil
+}
+
+func lastWord(line string) string {
+	if i := strings.LastIndexByte(line, ' '); i >= 0 {
+		return line[i+1:]
+	}
+	return line
+}
+
+// matching returns the candidates starting with prefix, ignoring case.
+func matching(candidates []string, prefix string) []string {
+	var result []string
+	for _, c := range candidates {
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(prefix)) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+func (s *Shell) customerIDs() []string {
+	ids := make([]string, 0, len(s.customers))
+	for _, c := range s.customers {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// fieldValues lists the distinct values of a text field, sorted, for
+// completing filter conditions. Values with spaces are left out since the
+// filter command splits on them.
+func (s *Shell) fieldValues(name string) []string {
+	field, ok := query.FieldByName(strings.ToLower(name))
+	if !ok || field.Kind != query.String {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var values []string
+	for _, row := range query.Rows(s.customers) {
+		value, _ := field.Get(row).(string)
+		if value != "" && !seen[value] && !strings.Contains(value, " ") {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
@@ -0,0 +1,245 @@
+//go:build linux
+
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// lineEditing reports that a terminal on stdin gets the line editor below.
+const lineEditing = true
+
+// terminal is a small line editor over a terminal in raw mode: left/right
+// and Home/End move the cursor, up/down walk the history, Tab completes the
+// last word, Ctrl-C drops the line and Ctrl-D on an empty line ends input.
+type terminal struct {
+	in       *os.File
+	reader   *bufio.Reader
+	out      io.Writer
+	complete func(string) []string
+	saved    syscall.Termios
+}
+
+// newLineReader uses the line editor when in is a terminal, and plain lines
+// otherwise, e.g. when commands are piped in.
+func newLineReader(in *os.File, out io.Writer, complete func(string) []string) lineReader {
+	saved, err := getTermios(in.Fd())
+	if err != nil {
+		return newPlainReader(in, out)
+	}
+
+	raw := saved
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON | syscall.BRKINT | syscall.INPCK | syscall.ISTRIP
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := setTermios(in.Fd(), &raw); err != nil {
+		return newPlainReader(in, out)
+	}
+
+	return &terminal{in: in, reader: bufio.NewReader(in), out: out, complete: complete, saved: saved}
+}
+
+func getTermios(fd uintptr) (syscall.Termios, error) {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return t, errno
+	}
+	return t, nil
+}
+
+func setTermios(fd uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCSETS, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// Close puts the terminal back the way it was found.
+func (t *terminal) Close() error {
+	return setTermios(t.in.Fd(), &t.saved)
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyTab       = 9
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyBackspace = 127
+	keyCtrlH     = 8
+)
+
+func (t *terminal) ReadLine(prompt string, history []string) (string, error) {
+	var line []rune
+	cursor := 0
+	// entry indexes history; len(history) is the line being typed, which
+	// is kept in draft while browsing.
+	entry := len(history)
+	var draft []rune
+
+	redraw := func() {
+		fmt.Fprintf(t.out, "\r\x1b[K%s%s", prompt, string(line))
+		if back := len(line) - cursor; back > 0 {
+			fmt.Fprintf(t.out, "\x1b[%dD", back)
+		}
+	}
+	show := func(r []rune) {
+		line = append([]rune(nil), r...)
+		cursor = len(line)
+		redraw()
+	}
+
+	fmt.Fprint(t.out, prompt)
+	for {
+		r, _, err := t.reader.ReadRune()
+		if err != nil {
+			fmt.Fprint(t.out, "\r\n")
+			return "", err
+		}
+
+		switch r {
+		case keyEnter, '\n':
+			fmt.Fprint(t.out, "\r\n")
+			return string(line), nil
+
+		case keyCtrlD:
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+
+		case keyCtrlC:
+			fmt.Fprint(t.out, "^C\r\n")
+			line, cursor = nil, 0
+			fmt.Fprint(t.out, prompt)
+
+		case keyBackspace, keyCtrlH:
+			if cursor > 0 {
+				line = append(line[:cursor-1], line[cursor:]...)
+				cursor--
+				redraw()
+			}
+
+		case keyCtrlA:
+			cursor = 0
+			redraw()
+
+		case keyCtrlE:
+			cursor = len(line)
+			redraw()
+
+		case keyCtrlU:
+			line, cursor = line[cursor:], 0
+			redraw()
+
+		case keyTab:
+			t.completeLine(&line, &cursor)
+			redraw()
+
+		case keyEscape:
+			switch t.escape() {
+			case 'A':
+				if entry > 0 {
+					if entry == len(history) {
+						draft = line
+					}
+					entry--
+					show([]rune(history[entry]))
+				}
+			case 'B':
+				if entry < len(history) {
+					entry++
+					if entry == len(history) {
+						show(draft)
+					} else {
+						show([]rune(history[entry]))
+					}
+				}
+			case 'C':
+				if cursor < len(line) {
+					cursor++
+					redraw()
+				}
+			case 'D':
+				if cursor > 0 {
+					cursor--
+					redraw()
+				}
+			case 'H':
+				cursor = 0
+				redraw()
+			case 'F':
+				cursor = len(line)
+				redraw()
+			}
+
+		default:
+			if r >= ' ' {
+				line = append(line[:cursor], append([]rune{r}, line[cursor:]...)...)
+				cursor++
+				redraw()
+			}
+		}
+	}
+}
+
+// escape reads the rest of an ESC [ X or ESC O X sequence and returns X.
+// Sequences with parameters, such as ESC [ 3 ~, return 0.
+func (t *terminal) escape() rune {
+	r, _, err := t.reader.ReadRune()
+	if err != nil || r != '[' && r != 'O' {
+		return 0
+	}
+	r, _, err = t.reader.ReadRune()
+	if err != nil {
+		return 0
+	}
+	for r >= '0' && r <= '9' || r == ';' {
+		if r, _, err = t.reader.ReadRune(); err != nil {
+			return 0
+		}
+		if r == '~' {
+			return 0
+		}
+	}
+	return r
+}
+
+// completeLine completes the word before the cursor. A single candidate is
+// taken whole, followed by a space unless it ends in '='; several are
+// extended to their common prefix and listed below the line.
+func (t *terminal) completeLine(line *[]rune, cursor *int) {
+	head := string((*line)[:*cursor])
+	tail := (*line)[*cursor:]
+	word := lastWord(head)
+
+	candidates := t.complete(head)
+	if len(candidates) == 0 {
+		return
+	}
+
+	replacement := commonPrefix(candidates)
+	if len(candidates) == 1 && !strings.HasSuffix(replacement, "=") {
+		replacement += " "
+	}
+	if len(candidates) > 1 && len(replacement) <= len(word) {
+		fmt.Fprintf(t.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	}
+	if len(replacement) < len(word) {
+		return
+	}
+
+	head = head[:len(head)-len(word)] + replacement
+	*line = append([]rune(head), tail...)
+	*cursor = len([]rune(head))
+}
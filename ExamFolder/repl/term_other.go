@@ -0,0 +1,17 @@
+//go:build !linux
+
+package repl
+
+import (
+	"io"
+	"os"
+)
+
+// lineEditing is false here: the raw-mode terminal handling is Linux only,
+// so lines are read as typed, without arrow-key history or tab completion.
+const lineEditing = false
+
+// newLineReader reads plain lines; line editing is only built on Linux.
+func newLineReader(in *os.File, out io.Writer, complete func(string) []string) lineReader {
+	return newPlainReader(in, out)
+}
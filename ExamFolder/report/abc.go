@@ -0,0 +1,27 @@
+package report
+
+import (
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// AddABC appends an ABC classification and its per-class summary. With
+// money set, values are minor units and render as amounts.
+func AddABC(r *Report, id, title, valueColumn string, items []task.ABCItem, money bool) {
+	value := func(v int64) any {
+		if money {
+			return store.Minor(v, "")
+		}
+		return v
+	}
+
+	s := r.Add(id, title, "rank", "id", "name", valueColumn, "share", "cumulative_share", "class")
+	for _, item := range items {
+		s.AddRow(item.Rank, item.ID, item.Name, value(item.Value), item.Share, item.Cumulative, string(item.Class))
+	}
+
+	classes := r.Add(id+"-classes", title+": summary per class", "class", "items", "item_share", valueColumn, "share")
+	for _, c := range task.ABCClasses(items) {
+		classes.AddRow(string(c.Class), c.Items, c.ItemShare, value(c.Value), c.Share)
+	}
+}
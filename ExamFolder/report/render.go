@@ -0,0 +1,218 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"ExamFolder/store"
+)
+
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+)
+
+// Formats lists every supported output format.
+var Formats = []Format{FormatText, FormatJSON, FormatCSV, FormatMarkdown}
+
+// ParseFormat accepts a format name such as "json" or "md".
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "text", "txt":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("report: unknown format %q", name)
+	}
+}
+
+// Render writes the report in the given format.
+func Render(w io.Writer, r Report, format Format) error {
+	switch format {
+	case FormatText:
+		return RenderText(w, r)
+	case FormatJSON:
+		return RenderJSON(w, r)
+	case FormatCSV:
+		return RenderCSV(w, r)
+	case FormatMarkdown:
+		return RenderMarkdown(w, r)
+	default:
+		return fmt.Errorf("report: unknown format %q", format)
+	}
+}
+
+// RenderJSON writes every section with its rows as objects keyed by column.
+func RenderJSON(w io.Writer, r Report) error {
+	type jsonSection struct {
+		ID    string    `json:"id"`
+		Title string    `json:"title"`
+		Rows  []jsonRow `json:"rows"`
+	}
+
+	sections := make([]jsonSection, 0, len(r.Sections))
+	for _, section := range r.Sections {
+		rows := make([]jsonRow, 0, len(section.Rows))
+		for _, row := range section.Rows {
+			rows = append(rows, jsonRow{columns: section.Columns, values: row})
+		}
+		sections = append(sections, jsonSection{ID: section.ID, Title: section.Title, Rows: rows})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]any{"sections": sections})
+}
+
+// jsonRow encodes a row as an object whose keys keep the column order.
+type jsonRow struct {
+	columns []string
+	values  []any
+}
+
+func (r jsonRow) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, column := range r.columns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(cell(r.values, i))
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+// RenderCSV writes the report in long form, one value per line, so sections
+// with different columns fit in a single file.
+func RenderCSV(w io.Writer, r Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"section", "title", "row", "column", "value"}); err != nil {
+		return err
+	}
+
+	for _, section := range r.Sections {
+		for i, row := range section.Rows {
+			for j, column := range section.Columns {
+				record := []string{section.ID, section.Title, strconv.Itoa(i + 1), column, formatValue(cell(row, j))}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// RenderMarkdown writes one heading and table per section.
+func RenderMarkdown(w io.Writer, r Report) error {
+	out := &errWriter{w: w}
+	for i, section := range r.Sections {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "## %s\n\n", section.Title)
+		fmt.Fprintf(out, "| %s |\n", strings.Join(section.Columns, " | "))
+		fmt.Fprintf(out, "|%s\n", strings.Repeat(" --- |", len(section.Columns)))
+		for _, row := range section.Rows {
+			cells := make([]string, len(section.Columns))
+			for j := range cells {
+				cells[j] = strings.ReplaceAll(formatValue(cell(row, j)), "|", `\|`)
+			}
+			fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+		}
+	}
+	return out.err
+}
+
+// RenderText writes aligned plain-text tables for the console.
+func RenderText(w io.Writer, r Report) error {
+	out := &errWriter{w: w}
+	for i, section := range r.Sections {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "%s\n", section.Title)
+
+		table := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, strings.Join(section.Columns, "\t"))
+		for _, row := range section.Rows {
+			cells := make([]string, len(section.Columns))
+			for j := range cells {
+				cells[j] = formatValue(cell(row, j))
+			}
+			fmt.Fprintln(table, strings.Join(cells, "\t"))
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+	return out.err
+}
+
+// cell returns the value of column j, or nil when the row is short, as rows
+// set on Section.Rows directly may be.
+func cell(row []any, j int) any {
+	if j < len(row) {
+		return row[j]
+	}
+	return nil
+}
+
+// errWriter keeps the first write error so a renderer can make many
+// writes and check once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case store.Money:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
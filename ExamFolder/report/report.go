@@ -0,0 +1,236 @@
+package report
+
+import (
+	"fmt"
+	"math"
+
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// Section is one table of a report. Rows hold typed values (store.Money,
+// int, float64, string) so each renderer can format them its own way.
+type Section struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+// Report collects the results of the tasks as renderable sections.
+type Report struct {
+	Sections []*Section `json:"sections"`
+}
+
+// Add appends a section and returns it so rows can be added.
+func (r *Report) Add(id, title string, columns ...string) *Section {
+	section := &Section{ID: id, Title: title, Columns: columns}
+	r.Sections = append(r.Sections, section)
+	return section
+}
+
+// AddRow appends one row; values line up with Columns. A short row is
+// padded with nil, which renders empty; more values than columns is a
+// programming error and panics.
+func (s *Section) AddRow(values ...any) {
+	if len(values) > len(s.Columns) {
+		panic(fmt.Sprintf("report: section %s: row has %d values for %d columns", s.ID, len(values), len(s.Columns)))
+	}
+	for len(values) < len(s.Columns) {
+		values = append(values, nil)
+	}
+	s.Rows = append(s.Rows, values)
+}
+
+// Build runs the numbered tasks against customers. An empty tasks list
+// means every task.
+func Build(customers []store.Customer, tasks []int) (Report, error) {
+	if len(tasks) == 0 {
+		for _, info := range task.Tasks {
+			tasks = append(tasks, info.Number)
+		}
+	}
+
+	aggregate := task.AggregateCustomers(customers)
+	var r Report
+	for _, number := range tasks {
+		info, ok := task.TaskInfo(number)
+		if !ok {
+			return Report{}, fmt.Errorf("report: unknown task %d", number)
+		}
+		if !addAggregateTask(&r, info, aggregate) {
+			addTask(&r, info, customers)
+		}
+	}
+
+	return r, nil
+}
+
+// aggregateTasks are the tasks answered from a task.Aggregate alone.
+var aggregateTasks = map[int]bool{1: true, 2: true, 5: true, 6: true, 12: true}
+
+// Streamable reports whether every numbered task can be built from a
+// task.Aggregate, see BuildAggregate. An empty list, meaning every task,
+// cannot.
+func Streamable(tasks []int) bool {
+	for _, number := range tasks {
+		if !aggregateTasks[number] {
+			return false
+		}
+	}
+	return len(tasks) > 0
+}
+
+// BuildAggregate runs the numbered tasks against an Aggregate built with
+// task.AggregateStream, so the dataset never has to be loaded whole. Every
+// task must be Streamable.
+func BuildAggregate(aggregate *task.Aggregate, tasks []int) (Report, error) {
+	var r Report
+	for _, number := range tasks {
+		info, ok := task.TaskInfo(number)
+		if !ok {
+			return Report{}, fmt.Errorf("report: unknown task %d", number)
+		}
+		if !addAggregateTask(&r, info, aggregate) {
+			return Report{}, fmt.Errorf("report: task %d needs the whole dataset", number)
+		}
+	}
+
+	return r, nil
+}
+
+// money rounds a statistic computed over Money.Float64 values back to an
+// amount.
+func money(major float64) store.Money {
+	return store.Minor(int64(math.Round(major*store.MinorUnits)), "")
+}
+
+var customerColumns = []string{"customer_id", "first_name", "last_name", "cash", "basket_total"}
+
+func customerRow(customer store.Customer) []any {
+	return []any{customer.ID, customer.FirstName, customer.LastName, customer.Cash, customer.Basket.Total}
+}
+
+var productColumns = []string{"product_id", "category", "name", "price", "quantity"}
+
+func productRow(product store.Product) []any {
+	return []any{product.ID, product.Category, product.Name, product.Price, product.Quantity}
+}
+
+func taskHeading(info task.Info) (id, title string) {
+	return fmt.Sprintf("task-%d", info.Number), fmt.Sprintf("Task %d: %s", info.Number, info.Title)
+}
+
+// addAggregateTask adds the tasks in aggregateTasks and reports whether
+// info was one of them.
+func addAggregateTask(r *Report, info task.Info, aggregate *task.Aggregate) bool {
+	id, title := taskHeading(info)
+	switch info.Number {
+	case 1:
+		s := r.Add(id, title, "customers", "total_cash", "total_spent")
+		s.AddRow(aggregate.Customers, aggregate.TotalCash, aggregate.TotalSpent)
+
+	case 2:
+		s := r.Add(id, title, customerColumns...)
+		if aggregate.Customers > 0 {
+			s.AddRow(customerRow(aggregate.TopSpender)...)
+		}
+
+	case 5:
+		s := r.Add(id, title, customerColumns...)
+		if aggregate.Customers > 0 {
+			s.AddRow(customerRow(aggregate.LowestSpender)...)
+		}
+
+	case 6:
+		s := r.Add(id, title, "category", "units")
+		if best := aggregate.BestSellingCategory(); best != "" {
+			s.AddRow(best, aggregate.CategoryQuantity[best])
+		}
+
+	case 12:
+		s := r.Add(id, title, "category", "revenue")
+		if best, ok := aggregate.MostProfitableCategory(); ok {
+			s.AddRow(best.Category, best.Revenue)
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
+func addTask(r *Report, info task.Info, customers []store.Customer) {
+	id, title := taskHeading(info)
+	allProducts := task.AllProducts(customers)
+
+	switch info.Number {
+	case 3:
+		s := r.Add(id, title, productColumns...)
+		if len(allProducts) > 0 {
+			s.AddRow(productRow(task.FindMostExpensiveProduct(allProducts))...)
+		}
+
+	case 4:
+		s := r.Add(id, title, productColumns...)
+		for _, product := range allProducts {
+			s.AddRow(productRow(product)...)
+		}
+
+	case 7:
+		s := r.Add(id, title, append([]string{"rank"}, productColumns...)...)
+		if len(allProducts) > 0 {
+			maxSold, minSold := task.FindMinMaxSoldProducts(customers)
+			s.AddRow(append([]any{"most"}, productRow(maxSold)...)...)
+			s.AddRow(append([]any{"least"}, productRow(minSold)...)...)
+		}
+
+	case 8:
+		s := r.Add(id, title, "product_lines", "customers", "average", "median", "p90", "stddev")
+		if average, ok := task.CalculateAverageQuantitySold(customers); ok {
+			s.AddRow(average.Products, average.Customers, average.Average, average.Stats.Median, average.Stats.P90, average.Stats.StdDev)
+		}
+
+	case 9:
+		s := r.Add(id, title, "customer_id", "first_name", "last_name", "product_lines", "total_product_lines")
+		if top, ok := task.TopCustomerByProductQuantity(customers); ok {
+			c := top.Customer
+			s.AddRow(c.ID, c.FirstName, c.LastName, len(c.Basket.Products), top.TotalProducts)
+		}
+
+	case 10:
+		s := r.Add(id, title, "product_id", "category", "name", "lines")
+		if mostSold, ok := task.MostSoldProduct(allProducts); ok {
+			p := mostSold.Product
+			s.AddRow(p.ID, p.Category, p.Name, mostSold.Count)
+		}
+
+	case 11:
+		s := r.Add(id, title, "average_spending", "median", "p90", "stddev", "top_customer_id", "top_customer_total")
+		if spending, ok := task.CalculateAverageSpending(customers); ok {
+			s.AddRow(spending.Average, money(spending.Stats.Median), money(spending.Stats.P90), money(spending.Stats.StdDev), spending.TopSpender.ID, spending.TopSpender.Basket.Total)
+		}
+
+	case 13:
+		s := r.Add(id, title, "customer_id", "first_name", "last_name", "product_id", "name", "price")
+		for _, purchase := range task.MostExpensivePurchaseByCustomer(customers) {
+			c, p := purchase.Customer, purchase.Product
+			s.AddRow(c.ID, c.FirstName, c.LastName, p.ID, p.Name, p.Price)
+		}
+
+	case 14:
+		s := r.Add(id, title, "customer_id", "first_name", "last_name", "category", "spent")
+		for _, spending := range task.MostExpensiveCategoryByCustomer(customers) {
+			c := spending.Customer
+			s.AddRow(c.ID, c.FirstName, c.LastName, spending.Category, spending.Spent)
+		}
+
+	case 15:
+		sold := task.TotalSoldQuantity(allProducts)
+		s := r.Add(id, title, "product_id", "name", "units")
+		for _, product := range sold.Products {
+			s.AddRow(product.ID, product.Name, product.Units)
+		}
+		s.AddRow("Total", "", sold.Total)
+	}
+}
@@ -0,0 +1,26 @@
+package report
+
+import (
+	"ExamFolder/rfm"
+)
+
+// AddRFM appends the RFM score of every customer and a summary per segment.
+func AddRFM(r *Report, results []rfm.Result) {
+	customers := r.Add("rfm-customers", "RFM scores", "customer_id", "first_name", "last_name", "recency_days", "frequency", "monetary", "r", "f", "m", "rfm", "segment")
+	for _, result := range results {
+		var recency, score any
+		if result.Recency != nil {
+			recency = *result.Recency
+		}
+		if result.R > 0 {
+			score = result.R
+		}
+		customers.AddRow(result.Customer.ID, result.Customer.FirstName, result.Customer.LastName,
+			recency, result.Frequency, result.Monetary, score, result.F, result.M, result.Code(), string(result.Segment))
+	}
+
+	segments := r.Add("rfm-segments", "RFM segments", "segment", "customers", "monetary")
+	for _, s := range rfm.Segments(results) {
+		segments.AddRow(string(s.Segment), s.Customers, s.Monetary)
+	}
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"ExamFolder/task"
+)
+
+var periodColumns = []string{"revenue", "units", "baskets", "growth", "moving_revenue", "moving_units"}
+
+func periodRow(period task.Period) []any {
+	var growth any
+	if period.Growth != nil {
+		growth = *period.Growth
+	}
+	return []any{period.Revenue, period.Units, period.Baskets, growth, period.MovingRevenue, period.MovingUnits}
+}
+
+// AddSeries appends the sections of a time series: the total per period,
+// then one row per period and category, and per period and product.
+// Undated sales get a section of their own when there are any.
+func AddSeries(r *Report, series task.TimeSeries) {
+	title := "Sales by " + string(series.Granularity)
+
+	total := r.Add("series-total", title, append([]string{"period"}, periodColumns...)...)
+	for _, period := range series.Total {
+		total.AddRow(append([]any{period.Label}, periodRow(period)...)...)
+	}
+
+	categories := r.Add("series-categories", title+" and category", append([]string{"period", "category"}, periodColumns...)...)
+	for _, s := range series.Categories {
+		for _, period := range s.Periods {
+			categories.AddRow(append([]any{period.Label, s.Key}, periodRow(period)...)...)
+		}
+	}
+
+	products := r.Add("series-products", title+" and product", append([]string{"period", "product_id", "name"}, periodColumns...)...)
+	for _, s := range series.Products {
+		for _, period := range s.Periods {
+			products.AddRow(append([]any{period.Label, s.Key, s.Name}, periodRow(period)...)...)
+		}
+	}
+
+	if series.Undated.Baskets > 0 {
+		undated := r.Add("series-undated", "Undated sales", "revenue", "units", "baskets")
+		undated.AddRow(series.Undated.Revenue, series.Undated.Units, series.Undated.Baskets)
+	}
+}
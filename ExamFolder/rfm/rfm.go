@@ -0,0 +1,258 @@
+package rfm
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"ExamFolder/store"
+)
+
+type Segment string
+
+const (
+	Champions          Segment = "Champions"
+	LoyalCustomers     Segment = "Loyal Customers"
+	PotentialLoyalists Segment = "Potential Loyalists"
+	NewCustomers       Segment = "New Customers"
+	BigSpenders        Segment = "Big Spenders"
+	NeedAttention      Segment = "Need Attention"
+	AtRisk             Segment = "At Risk"
+	CannotLoseThem     Segment = "Cannot Lose Them"
+	Hibernating        Segment = "Hibernating"
+)
+
+// Result is one customer's RFM measures and their quintile scores from 1
+// (worst) to 5 (best). Frequency counts basket lines over all orders and
+// Monetary is the consolidated basket total. Recency is the number of whole
+// days between the last dated order and the reference date; R is 0 when the
+// dataset has no dates at all.
+type Result struct {
+	Customer     store.Customer `json:"customer"`
+	Orders       int            `json:"orders"`
+	LastPurchase *time.Time     `json:"last_purchase,omitempty"`
+	Recency      *int           `json:"recency_days,omitempty"`
+	Frequency    int            `json:"frequency"`
+	Monetary     store.Money    `json:"monetary"`
+	R            int            `json:"r"`
+	F            int            `json:"f"`
+	M            int            `json:"m"`
+	Segment      Segment        `json:"segment"`
+}
+
+// Code is the scores written together, e.g. "545", or "-45" without
+// recency.
+func (r Result) Code() string {
+	code := "-"
+	if r.R > 0 {
+		code = strconv.Itoa(r.R)
+	}
+	return code + strconv.Itoa(r.F) + strconv.Itoa(r.M)
+}
+
+// Score computes the RFM results of every customer, in input order. Recency
+// is measured up to asOf; a zero asOf means the latest purchase in the
+// dataset. Customers without a dated order score R 1 when others have one.
+func Score(customers []store.Customer, asOf time.Time) []Result {
+	results := make([]Result, len(customers))
+	dated := false
+
+	for i, customer := range customers {
+		result := Result{
+			Customer: customer,
+			Orders:   len(customer.History()),
+			Monetary: customer.Basket.Total,
+		}
+		for _, order := range customer.History() {
+			result.Frequency += len(order.Products)
+			if at := order.PurchasedAt; at != nil && (result.LastPurchase == nil || at.After(*result.LastPurchase)) {
+				result.LastPurchase = at
+			}
+		}
+		dated = dated || result.LastPurchase != nil
+		results[i] = result
+	}
+
+	if dated {
+		if asOf.IsZero() {
+			for _, result := range results {
+				if result.LastPurchase != nil && result.LastPurchase.After(asOf) {
+					asOf = *result.LastPurchase
+				}
+			}
+		}
+		for i := range results {
+			if last := results[i].LastPurchase; last != nil {
+				days := max(int(asOf.Sub(*last).Hours()/24), 0)
+				results[i].Recency = &days
+			}
+		}
+	}
+
+	frequency := quintiles(results, func(r Result) int64 { return int64(r.Frequency) })
+	monetary := quintiles(results, func(r Result) int64 { return r.Monetary.Amount })
+	var recency []int
+	if dated {
+		recency = quintiles(results, func(r Result) int64 {
+			if r.Recency == nil {
+				return -1 << 62
+			}
+			return -int64(*r.Recency)
+		})
+	}
+
+	for i := range results {
+		results[i].F = frequency[i]
+		results[i].M = monetary[i]
+		if dated {
+			results[i].R = recency[i]
+		}
+		results[i].Segment = segment(results[i])
+	}
+	return results
+}
+
+// quintiles scores each result 1 to 5 by where value ranks, higher values
+// scoring higher. Equal values share the score of the first of them.
+func quintiles(results []Result, value func(Result) int64) []int {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return value(results[order[a]]) < value(results[order[b]])
+	})
+
+	scores := make([]int, len(results))
+	rank := 0
+	for position, i := range order {
+		if position == 0 || value(results[i]) != value(results[order[position-1]]) {
+			rank = position
+		}
+		scores[i] = 1 + rank*5/len(results)
+	}
+	return scores
+}
+
+// segment names a result from its scores. Without recency only frequency
+// and monetary value are used.
+func segment(r Result) Segment {
+	if r.R == 0 {
+		switch {
+		case r.F >= 4 && r.M >= 4:
+			return Champions
+		case r.F >= 4:
+			return LoyalCustomers
+		case r.M >= 4:
+			return BigSpenders
+		case r.F <= 2 && r.M <= 2:
+			return Hibernating
+		default:
+			return NeedAttention
+		}
+	}
+
+	switch {
+	case r.R >= 4 && r.F >= 4 && r.M >= 4:
+		return Champions
+	case r.R >= 3 && r.F >= 4:
+		return LoyalCustomers
+	case r.R >= 4 && r.F == 1:
+		return NewCustomers
+	case r.R >= 4:
+		return PotentialLoyalists
+	case r.R <= 2 && r.F >= 4 && r.M >= 4:
+		return CannotLoseThem
+	case r.R <= 2 && (r.F >= 3 || r.M >= 3):
+		return AtRisk
+	case r.R <= 2:
+		return Hibernating
+	default:
+		return NeedAttention
+	}
+}
+
+// SegmentCount is how many customers fall in a segment and what they spent.
+type SegmentCount struct {
+	Segment   Segment     `json:"segment"`
+	Customers int         `json:"customers"`
+	Monetary  store.Money `json:"monetary"`
+}
+
+// Segments counts results per segment, largest segment first and then by
+// name.
+func Segments(results []Result) []SegmentCount {
+	var counts []SegmentCount
+	index := make(map[Segment]int)
+
+	for _, result := range results {
+		i, ok := index[result.Segment]
+		if !ok {
+			i = len(counts)
+			index[result.Segment] = i
+			counts = append(counts, SegmentCount{Segment: result.Segment})
+		}
+		counts[i].Customers++
+		counts[i].Monetary = counts[i].Monetary.Add(result.Monetary)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Customers != counts[j].Customers {
+			return counts[i].Customers > counts[j].Customers
+		}
+		return counts[i].Segment < counts[j].Segment
+	})
+	return counts
+}
+
+// CSVHeader is the column layout written by WriteCSV.
+var CSVHeader = []string{
+	"customer_id", "first_name", "last_name", "orders", "last_purchase",
+	"recency_days", "frequency", "monetary", "r", "f", "m", "rfm", "segment",
+}
+
+// WriteCSV writes one row per result, for use outside this tool.
+func WriteCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(CSVHeader); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		lastPurchase, recency, score := "", "", ""
+		if r.LastPurchase != nil {
+			lastPurchase = r.LastPurchase.Format(time.RFC3339)
+		}
+		if r.Recency != nil {
+			recency = strconv.Itoa(*r.Recency)
+		}
+		if r.R > 0 {
+			score = strconv.Itoa(r.R)
+		}
+
+		record := []string{
+			r.Customer.ID,
+			r.Customer.FirstName,
+			r.Customer.LastName,
+			strconv.Itoa(r.Orders),
+			lastPurchase,
+			recency,
+			strconv.Itoa(r.Frequency),
+			r.Monetary.Decimal(),
+			score,
+			strconv.Itoa(r.F),
+			strconv.Itoa(r.M),
+			r.Code(),
+			string(r.Segment),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,156 @@
+package rfm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"ExamFolder/store"
+)
+
+func day(d int) *time.Time {
+	t := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, d-1)
+	return &t
+}
+
+// customer has one order of lines lines, each at the same price, adding up
+// to total.
+func customer(id string, purchasedAt *time.Time, lines int, total int64) store.Customer {
+	order := store.Basket{ID: "B" + id, PurchasedAt: purchasedAt, Total: store.Major(total, "")}
+	for i := 0; i < lines; i++ {
+		order.Products = append(order.Products, store.Product{ID: "P", Price: store.Major(total, "").Div(lines), Quantity: 1})
+	}
+	c := store.Customer{ID: id, Orders: []store.Basket{order}}
+	c.Basket = store.Consolidate(c.Orders)
+	return c
+}
+
+func testCustomers() []store.Customer {
+	return []store.Customer{
+		customer("C1", day(10), 5, 500),
+		customer("C2", day(9), 3, 300),
+		customer("C3", day(1), 2, 300),
+		customer("C4", day(-28), 2, 100),
+		customer("C5", nil, 1, 50),
+	}
+}
+
+func TestScore(t *testing.T) {
+	results := Score(testCustomers(), time.Time{})
+
+	// Recency is counted from the latest purchase, March 10. C5 is undated
+	// and ranks last; C3 and C4 tie on frequency and C2 and C3 on monetary,
+	// so each pair shares the score of the lower position.
+	want := []struct {
+		id      string
+		recency int
+		code    string
+		segment Segment
+	}{
+		{"C1", 0, "555", Champions},
+		{"C2", 1, "443", LoyalCustomers},
+		{"C3", 9, "323", NeedAttention},
+		{"C4", 38, "222", Hibernating},
+		{"C5", -1, "111", Hibernating},
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Customer.ID != w.id || r.Code() != w.code || r.Segment != w.segment {
+			t.Errorf("result %d = %s %s %s, want %s %s %s", i, r.Customer.ID, r.Code(), r.Segment, w.id, w.code, w.segment)
+		}
+		switch {
+		case w.recency < 0 && r.Recency != nil:
+			t.Errorf("%s recency = %d, want none", w.id, *r.Recency)
+		case w.recency >= 0 && (r.Recency == nil || *r.Recency != w.recency):
+			t.Errorf("%s recency = %v, want %d", w.id, r.Recency, w.recency)
+		}
+	}
+
+	if results[0].Frequency != 5 || results[0].Monetary != store.Major(500, "") || results[0].Orders != 1 {
+		t.Errorf("C1 measures = %+v", results[0])
+	}
+}
+
+func TestScoreAsOf(t *testing.T) {
+	results := Score(testCustomers(), *day(20))
+	if r := results[0].Recency; r == nil || *r != 10 {
+		t.Errorf("C1 recency as of March 20 = %v, want 10", r)
+	}
+}
+
+func TestScoreUndated(t *testing.T) {
+	var customers []store.Customer
+	for _, c := range testCustomers() {
+		c.Orders[0].PurchasedAt = nil
+		c.Basket = store.Consolidate(c.Orders)
+		customers = append(customers, c)
+	}
+
+	results := Score(customers, time.Time{})
+	codes := make([]string, len(results))
+	for i, r := range results {
+		codes[i] = r.Code() + " " + string(r.Segment)
+	}
+	want := []string{"-55 Champions", "-43 Loyal Customers", "-23 Need Attention", "-22 Hibernating", "-11 Hibernating"}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("codes = %q, want %q", codes, want)
+	}
+}
+
+func TestQuintiles(t *testing.T) {
+	tests := []struct {
+		values []int64
+		want   []int
+	}{
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, []int{1, 1, 2, 2, 3, 3, 4, 4, 5, 5}},
+		{[]int64{10, 1, 5}, []int{4, 1, 2}},
+		{[]int64{7, 7, 7}, []int{1, 1, 1}},
+		{[]int64{3, 1, 3, 2, 3}, []int{3, 1, 3, 2, 3}},
+		{[]int64{42}, []int{1}},
+	}
+	for _, test := range tests {
+		results := make([]Result, len(test.values))
+		for i, v := range test.values {
+			results[i].Frequency = int(v)
+		}
+		got := quintiles(results, func(r Result) int64 { return int64(r.Frequency) })
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("quintiles(%v) = %v, want %v", test.values, got, test.want)
+		}
+	}
+}
+
+func TestSegments(t *testing.T) {
+	got := Segments(Score(testCustomers(), time.Time{}))
+	want := []SegmentCount{
+		{Hibernating, 2, store.Major(150, "")},
+		{Champions, 1, store.Major(500, "")},
+		{LoyalCustomers, 1, store.Major(300, "")},
+		{NeedAttention, 1, store.Major(300, "")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segments = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, Score(testCustomers(), time.Time{})); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 || !reflect.DeepEqual(records[0], CSVHeader) {
+		t.Fatalf("got %d records with header %v", len(records), records[0])
+	}
+	if want := []string{"C5", "", "", "1", "", "", "1", "50", "1", "1", "1", "111", "Hibernating"}; !reflect.DeepEqual(records[5], want) {
+		t.Errorf("C5 row = %q, want %q", records[5], want)
+	}
+	if got := records[1][4]; got != "2024-03-10T00:00:00Z" {
+		t.Errorf("C1 last_purchase = %q", got)
+	}
+}
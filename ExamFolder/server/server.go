@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"ExamFolder/store"
+	"ExamFolder/task"
+)
+
+// DefaultTopN is the ranking size used when ?n= is not given.
+const DefaultTopN = 5
+
+// Server answers read-only JSON queries about a loaded dataset.
+//
+//	GET /customers
+//	GET /customers/{id}
+//	GET /customers/{id}/basket
+//	GET /customers/{id}/orders
+//	GET /products
+//	GET /analytics/top-spenders?n=
+//	GET /analytics/categories/revenue
+//	GET /analytics/categories/units
+//	GET /analytics/products/top?n=
+type Server struct {
+	customers []store.Customer
+	mux       *http.ServeMux
+}
+
+func New(customers []store.Customer) *Server {
+	s := &Server{customers: customers, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("/customers", s.handleCustomers)
+	s.mux.HandleFunc("/customers/", s.handleCustomer)
+	s.mux.HandleFunc("/products", s.handleProducts)
+	s.mux.HandleFunc("/analytics/top-spenders", s.handleTopSpenders)
+	s.mux.HandleFunc("/analytics/categories/revenue", s.handleCategoryRevenue)
+	s.mux.HandleFunc("/analytics/categories/units", s.handleCategoryUnits)
+	s.mux.HandleFunc("/analytics/products/top", s.handleTopProducts)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	// Paths no route matches would get the mux's plain-text 404; answer
+	// them in the same JSON shape as every other error.
+	if _, pattern := s.mux.Handler(r); pattern == "" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleCustomers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.customers)
+}
+
+// handleCustomer serves /customers/{id}, /customers/{id}/basket and
+// /customers/{id}/orders.
+func (s *Server) handleCustomer(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/customers/"), "/")
+	if id == "" {
+		s.handleCustomers(w, r)
+		return
+	}
+
+	customer, ok := s.findCustomer(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "customer "+id+" not found")
+		return
+	}
+
+	switch rest {
+	case "":
+		writeJSON(w, http.StatusOK, customer)
+	case "basket":
+		writeJSON(w, http.StatusOK, customer.Basket)
+	case "orders":
+		writeJSON(w, http.StatusOK, customer.History())
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) handleProducts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, task.ProductRevenues(s.customers))
+}
+
+func (s *Server) handleTopSpenders(w http.ResponseWriter, r *http.Request) {
+	n, ok := parseN(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, task.TopCustomers(s.customers, n))
+}
+
+func (s *Server) handleCategoryRevenue(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, task.TopCategoriesByRevenue(s.customers, 0))
+}
+
+func (s *Server) handleCategoryUnits(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, task.TopCategoriesByUnits(s.customers, 0))
+}
+
+func (s *Server) handleTopProducts(w http.ResponseWriter, r *http.Request) {
+	n, ok := parseN(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, task.TopProductsByRevenue(s.customers, n))
+}
+
+func (s *Server) findCustomer(id string) (store.Customer, bool) {
+	for _, customer := range s.customers {
+		if customer.ID == id {
+			return customer, true
+		}
+	}
+	return store.Customer{}, false
+}
+
+// parseN reads the ?n= ranking size, writing a 400 response when invalid.
+func parseN(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("n")
+	if value == "" {
+		return DefaultTopN, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		writeError(w, http.StatusBadRequest, "n must be a positive integer")
+		return 0, false
+	}
+	return n, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func testCustomers() []store.Customer {
+	customer := func(id string, cash int64, products ...store.Product) store.Customer {
+		basket := store.Basket{ID: "B" + id, Products: products}
+		basket.Total = basket.ProductsTotal()
+		return store.Customer{ID: id, FirstName: "First" + id, LastName: "Last" + id, Cash: store.Major(cash, ""), Basket: basket, Orders: []store.Basket{basket}}
+	}
+	product := func(id, category string, price int64, quantity int) store.Product {
+		return store.Product{ID: id, Category: category, Name: "Name" + id, Price: store.Major(price, ""), Quantity: quantity}
+	}
+
+	return []store.Customer{
+		customer("C1", 1000, product("P1", "Snack", 10, 2), product("P2", "Tech", 300, 1)),
+		customer("C2", 1000, product("P1", "Snack", 10, 5)),
+		customer("C3", 1000, product("P3", "Tech", 500, 1)),
+	}
+}
+
+func get(t *testing.T, target string, into any) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	New(testCustomers()).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("GET %s: Content-Type = %q, want application/json", target, got)
+	}
+	if into != nil && recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), into); err != nil {
+			t.Fatalf("GET %s: decoding %s: %v", target, recorder.Body, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestCustomers(t *testing.T) {
+	var customers []store.Customer
+	if code := get(t, "/customers", &customers); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(customers) != 3 || customers[0].ID != "C1" {
+		t.Errorf("got %+v, want C1, C2, C3", customers)
+	}
+}
+
+func TestCustomer(t *testing.T) {
+	var customer store.Customer
+	if code := get(t, "/customers/C2", &customer); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if customer.ID != "C2" || customer.FirstName != "FirstC2" {
+		t.Errorf("got %+v, want customer C2", customer)
+	}
+}
+
+func TestCustomerBasket(t *testing.T) {
+	var basket store.Basket
+	if code := get(t, "/customers/C1/basket", &basket); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if basket.ID != "BC1" || len(basket.Products) != 2 || basket.Total != store.Major(320, "") {
+		t.Errorf("got %+v, want basket BC1 with two lines totalling 320", basket)
+	}
+}
+
+func TestProducts(t *testing.T) {
+	var products []struct {
+		ID      string      `json:"id"`
+		Units   int         `json:"units"`
+		Revenue store.Money `json:"revenue"`
+	}
+	if code := get(t, "/products", &products); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+
+	revenue := make(map[string]store.Money)
+	for _, p := range products {
+		revenue[p.ID] = p.Revenue
+	}
+	if len(products) != 3 || revenue["P1"] != store.Major(70, "") {
+		t.Errorf("got %+v, want 3 products with P1 at 70", products)
+	}
+}
+
+func TestTopSpenders(t *testing.T) {
+	var customers []store.Customer
+	if code := get(t, "/analytics/top-spenders?n=2", &customers); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(customers) != 2 || customers[0].ID != "C3" || customers[1].ID != "C1" {
+		t.Errorf("got %+v, want C3 then C1", customers)
+	}
+}
+
+func TestCategoryRevenue(t *testing.T) {
+	var categories []struct {
+		Category string      `json:"category"`
+		Revenue  store.Money `json:"revenue"`
+	}
+	if code := get(t, "/analytics/categories/revenue", &categories); code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", code)
+	}
+	if len(categories) != 2 || categories[0].Category != "Tech" || categories[0].Revenue != store.Major(800, "") {
+		t.Errorf("got %+v, want Tech at 800 first", categories)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	for _, target := range []string{"/customers/C9", "/customers/C9/basket", "/customers/C1/unknown", "/", "/unknown", "/analytics", "/products/P1"} {
+		if code := get(t, target, nil); code != http.StatusNotFound {
+			t.Errorf("GET %s: status = %d, want 404", target, code)
+		}
+	}
+}
+
+func TestErrorShape(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		status int
+	}{
+		{http.MethodGet, "/unknown", http.StatusNotFound},
+		{http.MethodGet, "/customers/C9", http.StatusNotFound},
+		{http.MethodPost, "/customers", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/unknown", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		New(testCustomers()).ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d", tt.method, tt.target, recorder.Code, tt.status)
+		}
+		if got := recorder.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s %s: Content-Type = %q, want application/json", tt.method, tt.target, got)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body["error"] == "" {
+			t.Errorf("%s %s: body = %q, want a JSON error", tt.method, tt.target, recorder.Body)
+		}
+		if tt.status == http.StatusMethodNotAllowed && recorder.Header().Get("Allow") != "GET, HEAD" {
+			t.Errorf("%s %s: Allow = %q, want GET, HEAD", tt.method, tt.target, recorder.Header().Get("Allow"))
+		}
+	}
+}
+
+func TestBadN(t *testing.T) {
+	for _, target := range []string{"/analytics/top-spenders?n=abc", "/analytics/top-spenders?n=0", "/analytics/products/top?n=-1"} {
+		if code := get(t, target, nil); code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, code)
+		}
+	}
+}
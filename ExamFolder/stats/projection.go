@@ -0,0 +1,85 @@
+package stats
+
+import (
+	"ExamFolder/store"
+)
+
+// Project maps items to the numbers to describe.
+func Project[T any](items []T, value func(T) float64) []float64 {
+	values := make([]float64, len(items))
+	for i, item := range items {
+		values[i] = value(item)
+	}
+	return values
+}
+
+// Baskets returns every order of every customer.
+func Baskets(customers []store.Customer) []store.Basket {
+	var baskets []store.Basket
+	for _, customer := range customers {
+		baskets = append(baskets, customer.History()...)
+	}
+	return baskets
+}
+
+// Lines returns every product line of every order.
+func Lines(customers []store.Customer) []store.Product {
+	var lines []store.Product
+	for _, basket := range Baskets(customers) {
+		lines = append(lines, basket.Products...)
+	}
+	return lines
+}
+
+func units(products []store.Product) float64 {
+	total := 0
+	for _, product := range products {
+		total += product.Quantity
+	}
+	return float64(total)
+}
+
+// Projection is a named numeric view of a dataset.
+type Projection struct {
+	Name        string
+	Description string
+	Values      func([]store.Customer) []float64
+}
+
+func customers(value func(store.Customer) float64) func([]store.Customer) []float64 {
+	return func(c []store.Customer) []float64 { return Project(c, value) }
+}
+
+func baskets(value func(store.Basket) float64) func([]store.Customer) []float64 {
+	return func(c []store.Customer) []float64 { return Project(Baskets(c), value) }
+}
+
+func lines(value func(store.Product) float64) func([]store.Customer) []float64 {
+	return func(c []store.Customer) []float64 { return Project(Lines(c), value) }
+}
+
+// Projections lists the built-in projections, per customer, per order and
+// per product line.
+var Projections = []Projection{
+	{"customer.spending", "basket total over all orders", customers(func(c store.Customer) float64 { return c.Basket.Total.Float64() })},
+	{"customer.cash", "cash", customers(func(c store.Customer) float64 { return c.Cash.Float64() })},
+	{"customer.lines", "product lines over all orders", customers(func(c store.Customer) float64 { return float64(len(c.Basket.Products)) })},
+	{"customer.units", "units over all orders", customers(func(c store.Customer) float64 { return units(c.Basket.Products) })},
+	{"customer.orders", "number of orders", customers(func(c store.Customer) float64 { return float64(len(c.History())) })},
+	{"basket.total", "order total", baskets(func(b store.Basket) float64 { return b.Total.Float64() })},
+	{"basket.lines", "product lines per order", baskets(func(b store.Basket) float64 { return float64(len(b.Products)) })},
+	{"basket.units", "units per order", baskets(func(b store.Basket) float64 { return units(b.Products) })},
+	{"line.price", "unit price", lines(func(p store.Product) float64 { return p.Price.Float64() })},
+	{"line.quantity", "quantity", lines(func(p store.Product) float64 { return float64(p.Quantity) })},
+	{"line.total", "price * quantity", lines(func(p store.Product) float64 { return p.LineTotal().Float64() })},
+}
+
+// ProjectionByName finds a built-in projection.
+func ProjectionByName(name string) (Projection, bool) {
+	for _, p := range Projections {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Projection{}, false
+}
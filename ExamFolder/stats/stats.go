@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"math"
+	"slices"
+)
+
+// Summary describes a sample. Every field is zero for an empty sample.
+// StdDev is the population standard deviation.
+type Summary struct {
+	Count  int     `json:"count"`
+	Sum    float64 `json:"sum"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+}
+
+// Describe summarizes values. The input is not modified.
+func Describe(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	s := Summary{Count: len(sorted), Min: sorted[0], Max: sorted[len(sorted)-1]}
+	for _, v := range sorted {
+		s.Sum += v
+	}
+	s.Mean = s.Sum / float64(s.Count)
+
+	var squares float64
+	for _, v := range sorted {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(squares / float64(s.Count))
+
+	s.Median = Percentile(sorted, 50)
+	s.P90 = Percentile(sorted, 90)
+	s.P95 = Percentile(sorted, 95)
+	s.P99 = Percentile(sorted, 99)
+	return s
+}
+
+// Percentile returns the p-th percentile (0 to 100) of sorted values,
+// interpolating linearly between the two closest ranks. It returns 0 for
+// an empty slice.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	p = min(max(p, 0), 100)
+	position := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+// Bin is one histogram bucket holding values from Low up to High. Only the
+// last bin includes High.
+type Bin struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int     `json:"count"`
+}
+
+// Histogram splits the range of values into bins of equal width. All values
+// equal gives a single bin.
+func Histogram(values []float64, bins int) []Bin {
+	if len(values) == 0 || bins < 1 {
+		return nil
+	}
+
+	lowest, highest := slices.Min(values), slices.Max(values)
+	if lowest == highest {
+		return []Bin{{Low: lowest, High: highest, Count: len(values)}}
+	}
+
+	width := (highest - lowest) / float64(bins)
+	edges := make([]float64, bins+1)
+	for i := range edges {
+		edges[i] = lowest + width*float64(i)
+	}
+	edges[bins] = highest
+
+	return count(values, edges)
+}
+
+// QuantileHistogram splits values into bins holding about the same number
+// of values each, with edges at the quantiles. Bins whose edges coincide
+// are merged, so fewer bins may come back.
+func QuantileHistogram(values []float64, bins int) []Bin {
+	if len(values) == 0 || bins < 1 {
+		return nil
+	}
+
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	edges := []float64{sorted[0]}
+	for i := 1; i <= bins; i++ {
+		edge := Percentile(sorted, 100*float64(i)/float64(bins))
+		if edge > edges[len(edges)-1] {
+			edges = append(edges, edge)
+		}
+	}
+	if len(edges) == 1 {
+		return []Bin{{Low: sorted[0], High: sorted[0], Count: len(sorted)}}
+	}
+
+	return count(sorted, edges)
+}
+
+// count places values into the bins between consecutive edges.
+func count(values, edges []float64) []Bin {
+	result := make([]Bin, len(edges)-1)
+	for i := range result {
+		result[i] = Bin{Low: edges[i], High: edges[i+1]}
+	}
+
+	last := len(result) - 1
+	for _, v := range values {
+		i, found := slices.BinarySearch(edges, v)
+		if !found {
+			i--
+		}
+		result[min(max(i, 0), last)].Count++
+	}
+	return result
+}
@@ -0,0 +1,170 @@
+package stats
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestDescribe(t *testing.T) {
+	values := []float64{4, 1, 3, 2}
+	got := Describe(values)
+
+	want := Summary{
+		Count: 4, Sum: 10, Mean: 2.5,
+		Median: 2.5, P90: 3.7, P95: 3.85, P99: 3.97,
+		Min: 1, Max: 4, StdDev: math.Sqrt(1.25),
+	}
+	for _, f := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"count", float64(got.Count), float64(want.Count)},
+		{"sum", got.Sum, want.Sum},
+		{"mean", got.Mean, want.Mean},
+		{"median", got.Median, want.Median},
+		{"p90", got.P90, want.P90},
+		{"p95", got.P95, want.P95},
+		{"p99", got.P99, want.P99},
+		{"min", got.Min, want.Min},
+		{"max", got.Max, want.Max},
+		{"stddev", got.StdDev, want.StdDev},
+	} {
+		if !near(f.got, f.want) {
+			t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+		}
+	}
+
+	if !reflect.DeepEqual(values, []float64{4, 1, 3, 2}) {
+		t.Errorf("Describe sorted its input: %v", values)
+	}
+}
+
+func TestDescribeEmpty(t *testing.T) {
+	for _, values := range [][]float64{nil, {}} {
+		if got := Describe(values); got != (Summary{}) {
+			t.Errorf("Describe(%v) = %+v, want the zero Summary", values, got)
+		}
+	}
+}
+
+func TestDescribeSingle(t *testing.T) {
+	got := Describe([]float64{7})
+	if got.Count != 1 || got.Mean != 7 || got.Median != 7 || got.P99 != 7 || got.StdDev != 0 {
+		t.Errorf("Describe([7]) = %+v", got)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40, 50}
+	tests := []struct {
+		p, want float64
+	}{
+		{0, 10},
+		{25, 20},
+		{50, 30},
+		{60, 34},
+		{100, 50},
+		{-5, 10},
+		{150, 50},
+	}
+	for _, test := range tests {
+		if got := Percentile(sorted, test.p); !near(got, test.want) {
+			t.Errorf("Percentile(%v) = %v, want %v", test.p, got, test.want)
+		}
+	}
+
+	if got := Percentile(nil, 50); got != 0 {
+		t.Errorf("Percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		bins   int
+		want   []Bin
+	}{
+		{"equal width, max in last bin", []float64{1, 2, 3, 4}, 3, []Bin{
+			{Low: 1, High: 2, Count: 1},
+			{Low: 2, High: 3, Count: 1},
+			{Low: 3, High: 4, Count: 2},
+		}},
+		{"all equal", []float64{5, 5, 5}, 4, []Bin{{Low: 5, High: 5, Count: 3}}},
+		{"empty", nil, 3, nil},
+		{"no bins", []float64{1, 2}, 0, nil},
+	}
+	for _, test := range tests {
+		if got := Histogram(test.values, test.bins); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Histogram = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestQuantileHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		bins   int
+		want   []Bin
+	}{
+		// The 25th percentile equals the minimum, so the first two bins merge.
+		{"merged edges", []float64{5, 1, 1, 4, 1, 2, 1, 3}, 4, []Bin{
+			{Low: 1, High: 1.5, Count: 4},
+			{Low: 1.5, High: 3.25, Count: 2},
+			{Low: 3.25, High: 5, Count: 2},
+		}},
+		{"even split", []float64{1, 2, 3, 4}, 2, []Bin{
+			{Low: 1, High: 2.5, Count: 2},
+			{Low: 2.5, High: 4, Count: 2},
+		}},
+		{"all equal", []float64{2, 2}, 3, []Bin{{Low: 2, High: 2, Count: 2}}},
+		{"empty", nil, 3, nil},
+	}
+	for _, test := range tests {
+		if got := QuantileHistogram(test.values, test.bins); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: QuantileHistogram = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestProjections(t *testing.T) {
+	order := func(id string, price int64, quantity int) store.Basket {
+		b := store.Basket{ID: id, Products: []store.Product{{ID: "P" + id, Price: store.Major(price, ""), Quantity: quantity}}}
+		b.Total = b.ProductsTotal()
+		return b
+	}
+	customers := []store.Customer{
+		{ID: "C1", Orders: []store.Basket{order("B1", 10, 2), order("B2", 5, 1)}},
+		{ID: "C2"},
+	}
+
+	tests := map[string][]float64{
+		"customer.orders": {2, 0},
+		"basket.total":    {20, 5},
+		"line.quantity":   {2, 1},
+	}
+	for name, want := range tests {
+		projection, ok := ProjectionByName(name)
+		if !ok {
+			t.Fatalf("ProjectionByName(%q) not found", name)
+		}
+		if got := projection.Values(customers); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, ok := ProjectionByName("customer.nope"); ok {
+		t.Error("ProjectionByName found an unknown projection")
+	}
+	if got := Describe(Project([]store.Customer{}, func(store.Customer) float64 { return 1 })); got.Count != 0 {
+		t.Errorf("Describe of an empty projection = %+v", got)
+	}
+}
@@ -0,0 +1,367 @@
+package store
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CSVHeader is the column layout of the flat CSV format: one row per basket
+// line, with the customer and basket fields repeated on every row.
+var CSVHeader = []string{
+	"customer_id", "first_name", "last_name", "cash",
+	"basket_id", "product_id", "category", "name", "price", "quantity",
+	"basket_total",
+}
+
+// CSVPurchasedAt is an optional column after CSVHeader holding each
+// basket's RFC 3339 purchase time. It is only written when some basket is
+// dated.
+const CSVPurchasedAt = "purchased_at"
+
+// CSVCurrency is an optional column after CSVHeader, and after
+// CSVPurchasedAt when both are present, holding the currency of every
+// amount on the row. It is only written when the amounts name a currency.
+const CSVCurrency = "currency"
+
+// ReadCSV rebuilds the Customer/Basket/Product tree from flat CSV rows.
+// Customers and their orders keep the order in which they first appear. A
+// row with an empty product_id describes a basket with no products, and one
+// with an empty basket_id too a customer with no orders.
+func ReadCSV(r io.Reader) ([]Customer, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(header) < len(CSVHeader) {
+		return nil, fmt.Errorf("store: csv has %d columns, want at least %d", len(header), len(CSVHeader))
+	}
+	reader.FieldsPerRecord = len(header)
+	for i, column := range CSVHeader {
+		if header[i] != column {
+			return nil, fmt.Errorf("store: csv column %d is %q, want %q", i+1, header[i], column)
+		}
+	}
+	optional, err := csvOptionalColumns(header[len(CSVHeader):])
+	if err != nil {
+		return nil, err
+	}
+
+	var customers []Customer
+	index := make(map[string]int)
+	orderIndex := make(map[string]int)
+	orderOwner := make(map[string]string)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		row, err := parseCSVRecord(record, optional)
+		if err != nil {
+			return nil, fmt.Errorf("store: csv line %d: %w", line, err)
+		}
+
+		i, ok := index[row.customer.ID]
+		if !ok {
+			i = len(customers)
+			index[row.customer.ID] = i
+			customers = append(customers, row.customer)
+		}
+
+		customer := &customers[i]
+		if row.basket == nil {
+			continue
+		}
+
+		o, ok := orderIndex[row.basket.ID]
+		if !ok {
+			o = len(customer.Orders)
+			orderIndex[row.basket.ID] = o
+			orderOwner[row.basket.ID] = customer.ID
+			customer.Orders = append(customer.Orders, *row.basket)
+		} else if orderOwner[row.basket.ID] != customer.ID {
+			return nil, fmt.Errorf("store: csv line %d: basket %s already belongs to customer %s", line, row.basket.ID, orderOwner[row.basket.ID])
+		}
+		if row.product != nil {
+			customer.Orders[o].Products = append(customer.Orders[o].Products, *row.product)
+		}
+	}
+
+	if _, err := Currency(customers); err != nil {
+		return nil, err
+	}
+	for i := range customers {
+		customers[i].Basket = Consolidate(customers[i].Orders)
+	}
+	return customers, nil
+}
+
+// csvColumns holds the indexes of the optional columns, -1 when absent.
+type csvColumns struct {
+	purchasedAt int
+	currency    int
+}
+
+// csvOptionalColumns places the columns after CSVHeader: CSVPurchasedAt,
+// CSVCurrency, or both in that order.
+func csvOptionalColumns(extra []string) (csvColumns, error) {
+	columns := csvColumns{purchasedAt: -1, currency: -1}
+	next := 0
+	for _, name := range []string{CSVPurchasedAt, CSVCurrency} {
+		if next < len(extra) && extra[next] == name {
+			index := len(CSVHeader) + next
+			if name == CSVPurchasedAt {
+				columns.purchasedAt = index
+			} else {
+				columns.currency = index
+			}
+			next++
+		}
+	}
+	if next < len(extra) {
+		return csvColumns{}, fmt.Errorf("store: csv column %d is %q, want %s or %s", len(CSVHeader)+next+1, extra[next], CSVPurchasedAt, CSVCurrency)
+	}
+	return columns, nil
+}
+
+type csvRow struct {
+	customer Customer
+	basket   *Basket
+	product  *Product
+}
+
+func parseCSVRecord(record []string, optional csvColumns) (csvRow, error) {
+	currency := ""
+	if optional.currency >= 0 {
+		currency = record[optional.currency]
+	}
+	parse := func(text string) (Money, error) {
+		m, err := ParseMoney(text)
+		m.Currency = currency
+		return m, err
+	}
+
+	cash, err := parse(record[3])
+	if err != nil {
+		return csvRow{}, fmt.Errorf("cash: %w", err)
+	}
+
+	row := csvRow{
+		customer: Customer{
+			ID:        record[0],
+			FirstName: record[1],
+			LastName:  record[2],
+			Cash:      cash,
+		},
+	}
+
+	if record[4] == "" {
+		return row, nil
+	}
+
+	total, err := parse(record[10])
+	if err != nil {
+		return csvRow{}, fmt.Errorf("basket_total: %w", err)
+	}
+	row.basket = &Basket{ID: record[4], Total: total}
+	if optional.purchasedAt >= 0 && record[optional.purchasedAt] != "" {
+		purchasedAt, err := time.Parse(time.RFC3339, record[optional.purchasedAt])
+		if err != nil {
+			return csvRow{}, fmt.Errorf("%s: %w", CSVPurchasedAt, err)
+		}
+		row.basket.PurchasedAt = &purchasedAt
+	}
+
+	if record[5] == "" {
+		return row, nil
+	}
+
+	price, err := parse(record[8])
+	if err != nil {
+		return csvRow{}, fmt.Errorf("price: %w", err)
+	}
+	quantity, err := strconv.Atoi(record[9])
+	if err != nil {
+		return csvRow{}, fmt.Errorf("quantity: %w", err)
+	}
+
+	row.product = &Product{
+		ID:       record[5],
+		Category: record[6],
+		Name:     record[7],
+		Price:    price,
+		Quantity: quantity,
+	}
+
+	return row, nil
+}
+
+// WriteCSV flattens customers into the CSV layout read by ReadCSV, one
+// group of rows per order. The currency column holds one code for the whole
+// row, so amounts that name a currency cannot be mixed with ones that do
+// not.
+func WriteCSV(w io.Writer, customers []Customer) error {
+	currency, err := Currency(customers)
+	if err != nil {
+		return err
+	}
+	if currency != "" {
+		if err := checkTagged(customers); err != nil {
+			return err
+		}
+	}
+
+	writer := csv.NewWriter(w)
+
+	dated := false
+	for _, customer := range customers {
+		for _, order := range customer.History() {
+			dated = dated || order.PurchasedAt != nil
+		}
+	}
+
+	header := append([]string{}, CSVHeader...)
+	if dated {
+		header = append(header, CSVPurchasedAt)
+	}
+	if currency != "" {
+		header = append(header, CSVCurrency)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, customer := range customers {
+		prefix := []string{
+			customer.ID,
+			customer.FirstName,
+			customer.LastName,
+			customer.Cash.Decimal(),
+		}
+
+		orders := customer.History()
+		if len(orders) == 0 {
+			record := append(append([]string{}, prefix...), make([]string, len(header)-len(prefix))...)
+			if currency != "" {
+				record[len(record)-1] = currency
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, order := range orders {
+			basket := append(append([]string{}, prefix...), order.ID)
+			total := order.Total.Decimal()
+			var suffix []string
+			if dated {
+				purchasedAt := ""
+				if order.PurchasedAt != nil {
+					purchasedAt = order.PurchasedAt.Format(time.RFC3339)
+				}
+				suffix = append(suffix, purchasedAt)
+			}
+			if currency != "" {
+				suffix = append(suffix, currency)
+			}
+
+			if len(order.Products) == 0 {
+				record := append(append(append([]string{}, basket...), "", "", "", "", "", total), suffix...)
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, product := range order.Products {
+				record := append(append(append([]string{}, basket...),
+					product.ID,
+					product.Category,
+					product.Name,
+					product.Price.Decimal(),
+					strconv.Itoa(product.Quantity),
+					total,
+				), suffix...)
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// checkTagged reports an amount with no currency in a dataset whose other
+// amounts name one.
+func checkTagged(customers []Customer) error {
+	untagged := func(m Money, where string) error {
+		if m.Currency == "" {
+			return fmt.Errorf("store: csv: %s has no currency but other amounts do", where)
+		}
+		return nil
+	}
+
+	for _, customer := range customers {
+		if err := untagged(customer.Cash, "customer "+customer.ID+" cash"); err != nil {
+			return err
+		}
+		for _, order := range customer.History() {
+			if err := untagged(order.Total, "basket "+order.ID+" total"); err != nil {
+				return err
+			}
+			for _, product := range order.Products {
+				if err := untagged(product.Price, "basket "+order.ID+" product "+product.ID+" price"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CSVSource reads customers from a CSV file in the ReadCSV layout.
+type CSVSource struct {
+	Path string
+}
+
+func (s CSVSource) Load(ctx context.Context) ([]Customer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadCSV(file)
+}
+
+// Stream loads the file first: rows of one customer may be spread over
+// the whole file, so the tree cannot be rebuilt row by row.
+func (s CSVSource) Stream(ctx context.Context, fn func(Customer) error) error {
+	customers, err := s.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	return MemorySource{Customers: customers}.Stream(ctx, fn)
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	tests := []struct {
+		path  string
+		dated bool
+	}{
+		{"../data.Json/store_data.json", false},
+		{"../data.Json/orders_data.json", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			customers, err := ReadData(test.path)
+			if err != nil {
+				t.Fatalf("ReadData: %v", err)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, customers); err != nil {
+				t.Fatalf("WriteCSV: %v", err)
+			}
+
+			header, _, _ := strings.Cut(buf.String(), "\n")
+			if got := strings.HasSuffix(header, ","+CSVPurchasedAt); got != test.dated {
+				t.Errorf("header %q: %s column present = %v, want %v", header, CSVPurchasedAt, got, test.dated)
+			}
+
+			read, err := ReadCSV(&buf)
+			if err != nil {
+				t.Fatalf("ReadCSV: %v", err)
+			}
+			if !reflect.DeepEqual(read, customers) {
+				t.Errorf("round trip changed the data:\ngot  %+v\nwant %+v", read, customers)
+			}
+		})
+	}
+}
+
+func TestReadCSVPurchasedAt(t *testing.T) {
+	input := strings.Join([]string{
+		strings.Join(CSVHeader, ",") + "," + CSVPurchasedAt,
+		"C1,Ann,Lee,100,B1,P1,Snack,Chips,2,1,2,2024-03-01T10:00:00Z",
+		"C1,Ann,Lee,100,B2,P2,Snack,Nuts,3,2,6,",
+	}, "\n")
+
+	customers, err := ReadCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if len(customers) != 1 || len(customers[0].Orders) != 2 {
+		t.Fatalf("got %+v, want one customer with two orders", customers)
+	}
+
+	orders := customers[0].Orders
+	if orders[0].PurchasedAt == nil || orders[0].PurchasedAt.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("B1 purchased_at = %v, want 2024-03-01", orders[0].PurchasedAt)
+	}
+	if orders[1].PurchasedAt != nil {
+		t.Errorf("B2 purchased_at = %v, want undated", orders[1].PurchasedAt)
+	}
+	if got, want := customers[0].Basket.Total, Major(8, ""); got != want {
+		t.Errorf("consolidated total = %s, want %s", got, want)
+	}
+	if customers[0].Basket.ID != "B1" {
+		t.Errorf("consolidated basket ID = %s, want the dated order B1", customers[0].Basket.ID)
+	}
+}
+
+func TestCSVRoundTripCurrency(t *testing.T) {
+	input := `[
+		{"id": "C1", "first_name": "Ann", "last_name": "Lee",
+		 "cash": {"amount": 100, "currency": "UZS"},
+		 "basket": {"id": "B1", "total": {"amount": 7.5, "currency": "UZS"}, "products": [
+			{"id": "P1", "category": "Snack", "name": "Chips", "price": {"amount": 2.5, "currency": "UZS"}, "quantity": 3}
+		 ]}},
+		{"id": "C2", "first_name": "Bo", "last_name": "Kim", "cash": {"amount": 5, "currency": "UZS"}}
+	]`
+	customers, err := ReaderSource{Reader: strings.NewReader(input)}.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, customers); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	if header, _, _ := strings.Cut(buf.String(), "\n"); !strings.HasSuffix(header, ","+CSVCurrency) {
+		t.Errorf("header %q has no %s column", header, CSVCurrency)
+	}
+
+	read, err := ReadCSV(&buf)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if !reflect.DeepEqual(read, customers) {
+		t.Errorf("round trip changed the data:\ngot  %+v\nwant %+v", read, customers)
+	}
+	if got := read[0].Basket.Products[0].Price; got != Minor(250, "UZS") {
+		t.Errorf("price = %#v, want 2.50 UZS", got)
+	}
+}
+
+func TestWriteCSVRejectsPartialCurrency(t *testing.T) {
+	customers := []Customer{
+		{ID: "C1", Cash: Major(1, "UZS")},
+		{ID: "C2", Cash: Major(1, "")},
+	}
+	if err := WriteCSV(&bytes.Buffer{}, customers); err == nil {
+		t.Error("WriteCSV accepted cash with and without a currency")
+	}
+}
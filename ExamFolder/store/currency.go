@@ -0,0 +1,52 @@
+package store
+
+import "fmt"
+
+// Currency returns the one currency the customers' amounts are given in, or
+// "" when none names a currency. Amounts in different currencies cannot be
+// added or compared, so it is an error for two of them to disagree. Every
+// loader checks this, which keeps Money arithmetic on loaded data safe.
+func Currency(customers []Customer) (string, error) {
+	var check currencyCheck
+	for _, customer := range customers {
+		if err := check.customer(customer); err != nil {
+			return "", err
+		}
+	}
+	return check.currency, nil
+}
+
+// currencyCheck remembers the first currency seen and where it was seen.
+type currencyCheck struct {
+	currency string
+	where    string
+}
+
+func (c *currencyCheck) add(m Money, where string) error {
+	switch {
+	case m.Currency == "" || m.Currency == c.currency:
+		return nil
+	case c.currency == "":
+		c.currency, c.where = m.Currency, where
+		return nil
+	default:
+		return fmt.Errorf("store: %s is in %s but %s is in %s; mixed currencies are not supported", where, m.Currency, c.where, c.currency)
+	}
+}
+
+func (c *currencyCheck) customer(customer Customer) error {
+	if err := c.add(customer.Cash, "customer "+customer.ID+" cash"); err != nil {
+		return err
+	}
+	for _, order := range customer.History() {
+		if err := c.add(order.Total, "basket "+order.ID+" total"); err != nil {
+			return err
+		}
+		for _, product := range order.Products {
+			if err := c.add(product.Price, "basket "+order.ID+" product "+product.ID+" price"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
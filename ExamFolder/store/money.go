@@ -0,0 +1,287 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MinorUnits is the number of minor units (cents, tiyin...) in one major unit.
+const MinorUnits = 100
+
+const minorDigits = 2
+
+// Money is an exact amount stored as integer minor units. Currency is an
+// optional ISO 4217 code; an empty currency combines with any other.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// Minor builds a Money from an amount in minor units.
+func Minor(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Major builds a Money from a whole number of major units.
+func Major(amount int64, currency string) Money {
+	return Money{Amount: amount * MinorUnits, Currency: currency}
+}
+
+// ParseMoney reads a decimal string such as "12000", "-4.5", "19.99" or
+// "1.2e3" without going through float64.
+func ParseMoney(s string) (Money, error) {
+	text := strings.TrimSpace(s)
+	if strings.ContainsAny(text, "eE") {
+		return parseExponent(s, text)
+	}
+	negative := false
+	switch {
+	case strings.HasPrefix(text, "-"):
+		negative = true
+		text = text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("store: invalid money amount %q", s)
+	}
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return Money{}, fmt.Errorf("store: invalid money amount %q", s)
+		}
+	}
+
+	if trimmed := strings.TrimRight(fraction, "0"); len(trimmed) > minorDigits {
+		return Money{}, fmt.Errorf("store: money amount %q has more than %d decimal places", s, minorDigits)
+	}
+	for len(fraction) < minorDigits {
+		fraction += "0"
+	}
+	fraction = fraction[:minorDigits]
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("store: invalid money amount %q: %w", s, err)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount}, nil
+}
+
+// parseExponent reads the exponent forms JSON allows, such as 1e3 or
+// 1.5E-1, exactly.
+func parseExponent(s, text string) (Money, error) {
+	value, ok := new(big.Rat).SetString(text)
+	if !ok || strings.Contains(text, "/") {
+		return Money{}, fmt.Errorf("store: invalid money amount %q", s)
+	}
+
+	value.Mul(value, big.NewRat(MinorUnits, 1))
+	if !value.IsInt() {
+		return Money{}, fmt.Errorf("store: money amount %q has more than %d decimal places", s, minorDigits)
+	}
+	if !value.Num().IsInt64() {
+		return Money{}, fmt.Errorf("store: money amount %q is out of range", s)
+	}
+	return Money{Amount: value.Num().Int64()}, nil
+}
+
+// SameCurrency reports whether m and other can be added or compared: their
+// currencies are equal or at least one of them is empty.
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == "" || other.Currency == "" || m.Currency == other.Currency
+}
+
+func (m Money) currency(other Money) string {
+	switch {
+	case m.Currency == "":
+		return other.Currency
+	case other.Currency == "" || other.Currency == m.Currency:
+		return m.Currency
+	default:
+		panic(fmt.Sprintf("store: cannot combine %s and %s amounts", m.Currency, other.Currency))
+	}
+}
+
+// Add returns m + other. Mixing two different non-empty currencies panics;
+// loading rejects such data, see Currency.
+func (m Money) Add(other Money) Money {
+	return Money{Amount: m.Amount + other.Amount, Currency: m.currency(other)}
+}
+
+// Sub returns m - other. Mixing two different non-empty currencies panics.
+func (m Money) Sub(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.currency(other)}
+}
+
+// Mul returns m multiplied by a whole quantity.
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// Div splits m into n parts, rounding half away from zero to the nearest
+// minor unit.
+func (m Money) Div(n int) Money {
+	return m.MulFrac(1, int64(n))
+}
+
+// MulFrac returns m * numerator / denominator rounded half away from zero
+// to the nearest minor unit.
+func (m Money) MulFrac(numerator, denominator int64) Money {
+	if denominator == 0 {
+		panic("store: money division by zero")
+	}
+	if denominator < 0 {
+		numerator, denominator = -numerator, -denominator
+	}
+
+	product := m.Amount * numerator
+	quotient := product / denominator
+	remainder := product % denominator
+	if remainder < 0 {
+		remainder = -remainder
+	}
+	if 2*remainder >= denominator {
+		if product < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+	return Money{Amount: quotient, Currency: m.Currency}
+}
+
+// Cmp compares two amounts, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) int {
+	m.currency(other)
+	switch {
+	case m.Amount < other.Amount:
+		return -1
+	case m.Amount > other.Amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Float64 converts to major units for display or statistics; never use the
+// result for further money arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.Amount) / MinorUnits
+}
+
+// String formats the amount in major units with two decimals, followed by
+// the currency code when there is one.
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.decimal(true)
+	}
+	return m.decimal(true) + " " + m.Currency
+}
+
+// decimal renders the amount in major units. Without fixed, trailing zero
+// decimals are dropped so 12000.00 reads back as the original 12000.
+func (m Money) decimal(fixed bool) string {
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	whole := amount / MinorUnits
+	fraction := amount % MinorUnits
+	if !fixed && fraction == 0 {
+		return fmt.Sprintf("%s%d", sign, whole)
+	}
+
+	text := fmt.Sprintf("%s%d.%0*d", sign, whole, minorDigits, fraction)
+	if !fixed {
+		text = strings.TrimRight(text, "0")
+	}
+	return text
+}
+
+// Decimal renders the amount in major units with as few decimals as
+// needed, e.g. "12000" or "19.9".
+func (m Money) Decimal() string {
+	return m.decimal(false)
+}
+
+type moneyObject struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON writes a plain JSON number, as in store_data.json, or an
+// {"amount", "currency"} object when the currency is known.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.Currency == "" {
+		return []byte(m.Decimal()), nil
+	}
+	return json.Marshal(moneyObject{Amount: json.Number(m.Decimal()), Currency: m.Currency})
+}
+
+// UnmarshalJSON accepts a JSON number, a decimal string, or an
+// {"amount", "currency"} object.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		var object moneyObject
+		if err := json.Unmarshal(data, &object); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(object.Amount.String())
+		if err != nil {
+			return err
+		}
+		parsed.Currency = object.Currency
+		*m = parsed
+		return nil
+	case len(data) > 0 && data[0] == '"':
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(text)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		var number json.Number
+		if err := json.Unmarshal(data, &number); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(number.String())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+}
@@ -0,0 +1,213 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"12000", 1200000},
+		{"19.99", 1999},
+		{"19.9", 1990},
+		{"0.05", 5},
+		{".5", 50},
+		{"5.", 500},
+		{"-4.5", -450},
+		{"+3", 300},
+		{" 7 ", 700},
+		{"1.500", 150},
+		{"1e3", 100000},
+		{"1.2e3", 120000},
+		{"1.5E-1", 15},
+		{"-2e0", -200},
+		{"1e-2", 1},
+		{"92233720368547758.07", 9223372036854775807},
+	}
+
+	for _, test := range tests {
+		got, err := ParseMoney(test.input)
+		if err != nil {
+			t.Errorf("ParseMoney(%q): %v", test.input, err)
+			continue
+		}
+		if got != Minor(test.want, "") {
+			t.Errorf("ParseMoney(%q) = %d minor units, want %d", test.input, got.Amount, test.want)
+		}
+	}
+}
+
+func TestParseMoneyErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "invalid"},
+		{".", "invalid"},
+		{"-", "invalid"},
+		{"abc", "invalid"},
+		{"1,5", "invalid"},
+		{"1.2.3", "invalid"},
+		{"--1", "invalid"},
+		{"0.001", "more than 2 decimal places"},
+		{"19.999", "more than 2 decimal places"},
+		{"1e-3", "more than 2 decimal places"},
+		{"1.2345e1", "more than 2 decimal places"},
+		{"1.2.30", "invalid"},
+		{"1/2e1", "invalid"},
+		{"1e", "invalid"},
+		{"1e30", "out of range"},
+		{"92233720368547758.08", "invalid"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseMoney(test.input)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ParseMoney(%q) error = %v, want %q", test.input, err, test.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	a, b := Minor(1050, "UZS"), Minor(250, "")
+
+	if got := a.Add(b); got != Minor(1300, "UZS") {
+		t.Errorf("Add = %#v, want 13.00 UZS", got)
+	}
+	if got := b.Sub(a); got != Minor(-800, "UZS") {
+		t.Errorf("Sub = %#v, want -8.00 UZS", got)
+	}
+	if got := b.Mul(3); got != Minor(750, "") {
+		t.Errorf("Mul = %#v, want 7.50", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(a) != 0 {
+		t.Errorf("Cmp(%s, %s) is inconsistent", a, b)
+	}
+
+	// MulFrac and Div round half away from zero.
+	fracs := []struct {
+		amount, numerator, denominator, want int64
+	}{
+		{100, 1, 3, 33},
+		{200, 1, 3, 67},
+		{5, 1, 2, 3},
+		{-5, 1, 2, -3},
+		{-100, 1, 3, -33},
+		{333, 10, 100, 33},
+		{335, 10, 100, 34},
+		{100, 1, -3, -33},
+	}
+	for _, f := range fracs {
+		if got := Minor(f.amount, "").MulFrac(f.numerator, f.denominator); got.Amount != f.want {
+			t.Errorf("%d * %d / %d = %d, want %d", f.amount, f.numerator, f.denominator, got.Amount, f.want)
+		}
+	}
+	if got := Minor(1000, "").Div(3); got.Amount != 333 {
+		t.Errorf("Div(3) = %d, want 333", got.Amount)
+	}
+
+	if got := Minor(-1205, "UZS").String(); got != "-12.05 UZS" {
+		t.Errorf("String = %q, want -12.05 UZS", got)
+	}
+	if got := Minor(1200000, "").Decimal(); got != "12000" {
+		t.Errorf("Decimal = %q, want 12000", got)
+	}
+}
+
+func TestMoneyMixedCurrenciesPanic(t *testing.T) {
+	usd, uzs := Major(1, "USD"), Major(1, "UZS")
+	if usd.SameCurrency(uzs) || !usd.SameCurrency(Major(1, "")) {
+		t.Error("SameCurrency is wrong")
+	}
+
+	for name, op := range map[string]func(){
+		"Add": func() { usd.Add(uzs) },
+		"Sub": func() { usd.Sub(uzs) },
+		"Cmp": func() { usd.Cmp(uzs) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s on USD and UZS did not panic", name)
+				}
+			}()
+			op()
+		}()
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{`12000`, Major(12000, "")},
+		{`1e3`, Major(1000, "")},
+		{`"19.99"`, Minor(1999, "")},
+		{`{"amount": 2.5, "currency": "UZS"}`, Minor(250, "UZS")},
+		{`null`, Money{}},
+	}
+	for _, test := range tests {
+		var got Money
+		if err := json.Unmarshal([]byte(test.input), &got); err != nil {
+			t.Errorf("Unmarshal(%s): %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", test.input, got, test.want)
+		}
+
+		data, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Money
+		if err := json.Unmarshal(data, &back); err != nil || back != got {
+			t.Errorf("round trip of %s through %s = %#v, %v", test.input, data, back, err)
+		}
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`0.001`), &m); err == nil {
+		t.Error("Unmarshal accepted 0.001")
+	}
+}
+
+func TestLoadersRejectMixedCurrencies(t *testing.T) {
+	input := `[
+		{"id": "C1", "cash": {"amount": 10, "currency": "USD"}},
+		{"id": "C2", "cash": 5, "basket": {"id": "B2", "total": {"amount": 3, "currency": "UZS"}, "products": [
+			{"id": "P1", "price": {"amount": 3, "currency": "UZS"}, "quantity": 1}
+		]}}
+	]`
+	const want = "basket B2 total is in UZS but customer C1 cash is in USD"
+
+	_, err := ReaderSource{Reader: strings.NewReader(input)}.Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Load error = %v, want %q", err, want)
+	}
+
+	err = DecodeStream(context.Background(), strings.NewReader(input), func(Customer) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("DecodeStream error = %v, want %q", err, want)
+	}
+
+	mixedRow := `{"id": "C1", "cash": {"amount": 10, "currency": "USD"}, "basket": {"id": "B1", "total": 1,
+		"products": [{"id": "P1", "price": {"amount": 1, "currency": "UZS"}, "quantity": 1}]}}`
+	var customer Customer
+	if err := json.Unmarshal([]byte(mixedRow), &customer); err == nil || !strings.Contains(err.Error(), "mixed currencies") {
+		t.Errorf("Customer.UnmarshalJSON error = %v, want mixed currencies", err)
+	}
+
+	csv := strings.Join(CSVHeader, ",") + "," + CSVCurrency + "\n" +
+		"C1,Ann,Lee,10,,,,,,,,USD\n" +
+		"C2,Bo,Kim,10,,,,,,,,UZS\n"
+	if _, err := ReadCSV(strings.NewReader(csv)); err == nil || !strings.Contains(err.Error(), "mixed currencies") {
+		t.Errorf("ReadCSV error = %v, want mixed currencies", err)
+	}
+}
@@ -0,0 +1,238 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// History returns the customer's orders. A customer built with only a
+// Basket has that basket as its single order, unless it is empty.
+func (c Customer) History() []Basket {
+	if len(c.Orders) > 0 {
+		return c.Orders
+	}
+	if c.Basket.ID == "" && len(c.Basket.Products) == 0 {
+		return nil
+	}
+	return []Basket{c.Basket}
+}
+
+// legacy reports whether the customer fits the original single-basket
+// shape: at most one order, and that one undated.
+func (c Customer) legacy() bool {
+	orders := c.History()
+	return len(orders) == 0 || len(orders) == 1 && orders[0].PurchasedAt == nil
+}
+
+// Consolidate merges orders into one basket holding every product line and
+// the sum of their totals. The basket takes the ID and time of the latest
+// dated order, or of the last order when none is dated.
+func Consolidate(orders []Basket) Basket {
+	switch len(orders) {
+	case 0:
+		return Basket{}
+	case 1:
+		return orders[0]
+	}
+
+	latest := orders[len(orders)-1]
+	for _, order := range orders {
+		if order.PurchasedAt != nil && (latest.PurchasedAt == nil || order.PurchasedAt.After(*latest.PurchasedAt)) {
+			latest = order
+		}
+	}
+
+	basket := Basket{ID: latest.ID, PurchasedAt: latest.PurchasedAt}
+	for _, order := range orders {
+		basket.Products = append(basket.Products, order.Products...)
+		basket.Total = basket.Total.Add(order.Total)
+	}
+	return basket
+}
+
+// customerJSON is the wire shape of a Customer. Basket is only read when
+// orders is absent, and only written for legacy customers.
+type customerJSON struct {
+	ID        string   `json:"id"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Cash      Money    `json:"cash"`
+	Basket    *Basket  `json:"basket,omitempty"`
+	Orders    []Basket `json:"orders,omitempty"`
+}
+
+// MarshalJSON writes the legacy single-basket shape when the customer has
+// no more than one undated order, and the orders list otherwise. Both come
+// from History; the consolidated Basket is derived data and never written.
+func (c Customer) MarshalJSON() ([]byte, error) {
+	wire := customerJSON{ID: c.ID, FirstName: c.FirstName, LastName: c.LastName, Cash: c.Cash}
+	orders := c.History()
+	switch {
+	case !c.legacy():
+		wire.Orders = orders
+	case len(orders) == 1:
+		wire.Basket = &orders[0]
+	default:
+		wire.Basket = &Basket{}
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON reads both shapes. A legacy basket becomes the single
+// order; with orders, Basket is their consolidation.
+func (c *Customer) UnmarshalJSON(data []byte) error {
+	var wire customerJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	customer := Customer{ID: wire.ID, FirstName: wire.FirstName, LastName: wire.LastName, Cash: wire.Cash}
+	switch {
+	case wire.Orders != nil:
+		customer.Orders = wire.Orders
+	case wire.Basket != nil:
+		customer.Orders = []Basket{*wire.Basket}
+	}
+
+	// Consolidating adds the order totals, which needs one currency.
+	var check currencyCheck
+	if err := check.customer(customer); err != nil {
+		return err
+	}
+	customer.Basket = Consolidate(customer.Orders)
+	*c = customer
+	return nil
+}
+
+// Window selects orders by purchase time, from From inclusive to To
+// exclusive. A zero bound is open. Undated orders only fall inside a window
+// with both bounds open, or when IncludeUndated is set; see Undated for how
+// many a window leaves out.
+type Window struct {
+	From           time.Time `json:"from,omitempty"`
+	To             time.Time `json:"to,omitempty"`
+	IncludeUndated bool      `json:"include_undated,omitempty"`
+}
+
+// ParseWindow reads bounds as RFC 3339 times or YYYY-MM-DD dates; either may
+// be empty. A date in to includes that whole day.
+func ParseWindow(from, to string) (Window, error) {
+	var w Window
+	var err error
+
+	if from != "" {
+		if w.From, _, err = parseBound(from); err != nil {
+			return Window{}, fmt.Errorf("store: window from: %w", err)
+		}
+	}
+	if to != "" {
+		var dateOnly bool
+		if w.To, dateOnly, err = parseBound(to); err != nil {
+			return Window{}, fmt.Errorf("store: window to: %w", err)
+		}
+		if dateOnly {
+			w.To = w.To.AddDate(0, 0, 1)
+		}
+	}
+	if !w.From.IsZero() && !w.To.IsZero() && !w.From.Before(w.To) {
+		return Window{}, fmt.Errorf("store: window from %s is not before to %s", from, to)
+	}
+	return w, nil
+}
+
+func parseBound(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// IsZero reports whether both bounds are open.
+func (w Window) IsZero() bool {
+	return w.From.IsZero() && w.To.IsZero()
+}
+
+// Contains reports whether an order placed at t falls inside the window.
+func (w Window) Contains(t *time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	if t == nil {
+		return w.IncludeUndated
+	}
+	return (w.From.IsZero() || !t.Before(w.From)) && (w.To.IsZero() || t.Before(w.To))
+}
+
+// Undated counts the undated orders the window leaves out.
+func (w Window) Undated(customers []Customer) int {
+	if w.IsZero() || w.IncludeUndated {
+		return 0
+	}
+
+	undated := 0
+	for _, customer := range customers {
+		for _, order := range customer.History() {
+			if order.PurchasedAt == nil {
+				undated++
+			}
+		}
+	}
+	return undated
+}
+
+// Filter returns copies of the customers holding only the orders inside the
+// window, with Basket rebuilt from them. Customers with no order left are
+// kept with an empty basket so per-customer results still list them.
+func (w Window) Filter(customers []Customer) []Customer {
+	if w.IsZero() {
+		return customers
+	}
+
+	filtered := make([]Customer, 0, len(customers))
+	for _, customer := range customers {
+		var orders []Basket
+		for _, order := range customer.History() {
+			if w.Contains(order.PurchasedAt) {
+				orders = append(orders, order)
+			}
+		}
+		customer.Orders = orders
+		customer.Basket = Consolidate(orders)
+		filtered = append(filtered, customer)
+	}
+	return filtered
+}
+
+// Stream restricts a Streamer to the window: each customer is filtered as it
+// goes by, and Load filters the whole dataset the same way.
+func (w Window) Stream(source Streamer) *WindowStream {
+	return &WindowStream{Source: source, Window: w}
+}
+
+// WindowStream is a Streamer filtered through a Window. Undated counts the
+// undated orders left out so far, as Window.Undated does.
+type WindowStream struct {
+	Source  Streamer
+	Window  Window
+	Undated int
+}
+
+func (s *WindowStream) Load(ctx context.Context) ([]Customer, error) {
+	customers, err := s.Source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.Undated += s.Window.Undated(customers)
+	return s.Window.Filter(customers), nil
+}
+
+func (s *WindowStream) Stream(ctx context.Context, fn func(Customer) error) error {
+	return s.Source.Stream(ctx, func(customer Customer) error {
+		customers := []Customer{customer}
+		s.Undated += s.Window.Undated(customers)
+		return fn(s.Window.Filter(customers)[0])
+	})
+}
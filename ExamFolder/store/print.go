@@ -0,0 +1,40 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Printing customer and basket information
+func PrintCustomerInfo(customer Customer) {
+	FprintCustomerInfo(os.Stdout, customer)
+}
+
+// FprintCustomerInfo is PrintCustomerInfo writing to w.
+func FprintCustomerInfo(w io.Writer, customer Customer) {
+	fmt.Fprintf(w, "Name: %s, Last Name: %s, Customer Cash: %s\n",
+		customer.FirstName, customer.LastName, customer.Cash)
+
+	for _, product := range customer.Basket.Products {
+		fmt.Fprintf(w, "   Category: %s, Name: %s, Price: %s, Quantity: %d\n",
+			product.Category, product.Name, product.Price, product.Quantity)
+	}
+
+	fmt.Fprintf(w, "   Total Basket Amount: %s\n", customer.Basket.Total)
+	fmt.Fprintln(w, "------------------------------")
+}
+
+// Printing product information
+func PrintProductInfo(product Product) {
+	FprintProductInfo(os.Stdout, product)
+}
+
+// FprintProductInfo is PrintProductInfo writing to w.
+func FprintProductInfo(w io.Writer, product Product) {
+	fmt.Fprintf(w, "Category: %s\n", product.Category)
+	fmt.Fprintf(w, "Product name: %s\n", product.Name)
+	fmt.Fprintf(w, "Price: %s\n", product.Price)
+	fmt.Fprintf(w, "Quantity: %d\n", product.Quantity)
+	fmt.Fprintln(w, "------------------------------")
+}
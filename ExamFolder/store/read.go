@@ -1,43 +1,60 @@
 package store
 
 import (
-	"encoding/json"
-	"io/ioutil"
+	"context"
+	"time"
 )
 
 type Product struct {
-	ID       string  `json:"id"`
-	Category string  `json:"category"`
-	Name     string  `json:"name"`
-	Price    float64 `json:"price"`
-	Quantity int     `json:"quantity"`
+	ID       string `json:"id"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Price    Money  `json:"price"`
+	Quantity int    `json:"quantity"`
 }
 
+// Basket is one order. PurchasedAt is nil for undated baskets, such as
+// those in the legacy single-basket shape.
 type Basket struct {
-	ID       string    `json:"id"`
-	Products []Product `json:"products"`
-	Total    float64   `json:"total"`
+	ID          string     `json:"id"`
+	PurchasedAt *time.Time `json:"purchased_at,omitempty"`
+	Products    []Product  `json:"products"`
+	Total       Money      `json:"total"`
 }
 
+// Customer holds the order history in Orders, and in Basket all of those
+// orders consolidated into one, which is what the analyses aggregate over.
+// See History, Consolidate and Window.Filter.
 type Customer struct {
-	ID        string  `json:"id"`
-	FirstName string  `json:"first_name"`
-	LastName  string  `json:"last_name"`
-	Cash      float64 `json:"cash"`
-	Basket    Basket  `json:"basket"`
+	ID        string   `json:"id"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Cash      Money    `json:"cash"`
+	Basket    Basket   `json:"basket"`
+	Orders    []Basket `json:"orders,omitempty"`
 }
 
-func ReadData(filename string) ([]Customer, error) {
-	data, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, err
+// LineTotal is Price * Quantity.
+func (p Product) LineTotal() Money {
+	return p.Price.Mul(p.Quantity)
+}
+
+// ProductsTotal recomputes the basket total from its product lines.
+func (b Basket) ProductsTotal() Money {
+	var total Money
+	for _, product := range b.Products {
+		total = total.Add(product.LineTotal())
 	}
+	return total
+}
 
-	var customers []Customer
-	err = json.Unmarshal(data, &customers)
+// ReadData loads customers from a plain file path or from any source URI
+// understood by OpenSource (file://, stdin://, embed://).
+func ReadData(filename string) ([]Customer, error) {
+	source, err := OpenSource(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	return customers, nil
+	return source.Load(context.Background())
 }
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source is anything customers can be loaded from.
+type Source interface {
+	Load(ctx context.Context) ([]Customer, error)
+}
+
+// EmbeddedFS is the file system served by embed:// URIs. The main package
+// sets it to the data files compiled into the binary.
+var EmbeddedFS fs.FS
+
+// OpenSource picks a Source by URI scheme. A name without a scheme is
+// treated as a file path; files ending in .csv are read with ReadCSV.
+//
+//	file://data.Json/store_data.json
+//	stdin://
+//	embed://data.Json/store_data.json
+func OpenSource(uri string) (Source, error) {
+	scheme, path, found := strings.Cut(uri, "://")
+	if !found {
+		return fileSource(uri), nil
+	}
+
+	switch scheme {
+	case "file":
+		return fileSource(path), nil
+	case "stdin":
+		return ReaderSource{Reader: os.Stdin}, nil
+	case "embed":
+		if EmbeddedFS == nil {
+			return nil, fmt.Errorf("store: no embedded data available for %q", uri)
+		}
+		return EmbedSource{FS: EmbeddedFS, Path: path}, nil
+	default:
+		return nil, fmt.Errorf("store: unsupported source scheme %q", scheme)
+	}
+}
+
+// Open opens a file path, or a file:// or embed:// URI, for reading. It
+// serves auxiliary files such as stock lists the way OpenSource serves
+// datasets.
+func Open(uri string) (io.ReadCloser, error) {
+	scheme, path, found := strings.Cut(uri, "://")
+	if !found {
+		return os.Open(uri)
+	}
+
+	switch scheme {
+	case "file":
+		return os.Open(path)
+	case "embed":
+		if EmbeddedFS == nil {
+			return nil, fmt.Errorf("store: no embedded data available for %q", uri)
+		}
+		return EmbeddedFS.Open(path)
+	default:
+		return nil, fmt.Errorf("store: unsupported file scheme %q", scheme)
+	}
+}
+
+func fileSource(path string) Source {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return CSVSource{Path: path}
+	}
+	return FileSource{Path: path}
+}
+
+// FileSource reads customers from a JSON file on disk.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Load(ctx context.Context) ([]Customer, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decode(ctx, file)
+}
+
+// ReaderSource reads customers from a JSON stream such as os.Stdin.
+type ReaderSource struct {
+	Reader io.Reader
+}
+
+func (s ReaderSource) Load(ctx context.Context) ([]Customer, error) {
+	return decode(ctx, s.Reader)
+}
+
+// EmbedSource reads customers from a JSON file inside an fs.FS,
+// typically an embed.FS.
+type EmbedSource struct {
+	FS   fs.FS
+	Path string
+}
+
+func (s EmbedSource) Load(ctx context.Context) ([]Customer, error) {
+	file, err := s.FS.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decode(ctx, file)
+}
+
+// MemorySource serves customers that are already in memory.
+type MemorySource struct {
+	Customers []Customer
+}
+
+func (s MemorySource) Load(ctx context.Context) ([]Customer, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	customers := make([]Customer, len(s.Customers))
+	copy(customers, s.Customers)
+	return customers, nil
+}
+
+func decode(ctx context.Context, r io.Reader) ([]Customer, error) {
+	var customers []Customer
+	err := DecodeStream(ctx, r, func(customer Customer) error {
+		customers = append(customers, customer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customers, nil
+}
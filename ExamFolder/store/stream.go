@@ -0,0 +1,110 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Streamer is a Source that can also hand out customers one at a time,
+// so callers never need the whole dataset in memory.
+type Streamer interface {
+	Source
+	Stream(ctx context.Context, fn func(Customer) error) error
+}
+
+// StreamData walks the customers of a file path or source URI one by one.
+func StreamData(filename string, fn func(Customer) error) error {
+	source, err := OpenSource(filename)
+	if err != nil {
+		return err
+	}
+
+	streamer, ok := source.(Streamer)
+	if !ok {
+		return fmt.Errorf("store: source %q does not support streaming", filename)
+	}
+
+	return streamer.Stream(context.Background(), fn)
+}
+
+// DecodeStream reads a top-level JSON array of customers token by token and
+// calls fn for every element. Returning an error from fn stops the walk, as
+// does a customer whose amounts use another currency than those before it.
+func DecodeStream(ctx context.Context, r io.Reader, fn func(Customer) error) error {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("store: expected array of customers, got %v", token)
+	}
+
+	var check currencyCheck
+	for decoder.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var customer Customer
+		if err := decoder.Decode(&customer); err != nil {
+			return err
+		}
+		if err := check.customer(customer); err != nil {
+			return err
+		}
+		if err := fn(customer); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s FileSource) Stream(ctx context.Context, fn func(Customer) error) error {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return DecodeStream(ctx, file, fn)
+}
+
+func (s ReaderSource) Stream(ctx context.Context, fn func(Customer) error) error {
+	return DecodeStream(ctx, s.Reader, fn)
+}
+
+func (s EmbedSource) Stream(ctx context.Context, fn func(Customer) error) error {
+	file, err := s.FS.Open(s.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return DecodeStream(ctx, file, fn)
+}
+
+func (s MemorySource) Stream(ctx context.Context, fn func(Customer) error) error {
+	for _, customer := range s.Customers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(customer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
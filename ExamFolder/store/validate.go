@@ -0,0 +1,135 @@
+package store
+
+import (
+	"fmt"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule codes reported by Validate.
+const (
+	RuleMissingID            = "missing-id"
+	RuleDuplicateCustomerID  = "duplicate-customer-id"
+	RuleDuplicateBasketID    = "duplicate-basket-id"
+	RuleDuplicateProductLine = "duplicate-product-line"
+	RuleEmptyBasket          = "empty-basket"
+	RuleNonPositiveQuantity  = "non-positive-quantity"
+	RuleNegativePrice        = "negative-price"
+	RuleBasketTotalMismatch  = "basket-total-mismatch"
+	RuleInsufficientCash     = "insufficient-cash"
+)
+
+// ValidationIssue describes one problem found in a dataset. Path points at
+// the offending value, e.g. [3].basket.products[1].price, or
+// [3].orders[0].products[1].price for customers with an order history.
+type ValidationIssue struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s %s %s: %s", i.Severity, i.Path, i.Code, i.Message)
+}
+
+// Validate checks basket totals, quantities, prices, ID uniqueness and
+// whether each customer's cash covers their basket. Every order is checked;
+// cash is checked against all orders together.
+func Validate(customers []Customer) []ValidationIssue {
+	var issues []ValidationIssue
+	report := func(severity Severity, path, code, format string, args ...any) {
+		issues = append(issues, ValidationIssue{
+			Severity: severity,
+			Path:     path,
+			Code:     code,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	customerIDs := make(map[string]int)
+	basketIDs := make(map[string]int)
+
+	for i, customer := range customers {
+		path := fmt.Sprintf("[%d]", i)
+
+		if customer.ID == "" {
+			report(SeverityError, path+".id", RuleMissingID, "customer has no id")
+		} else if first, ok := customerIDs[customer.ID]; ok {
+			report(SeverityError, path+".id", RuleDuplicateCustomerID, "customer id %s already used at [%d]", customer.ID, first)
+		} else {
+			customerIDs[customer.ID] = i
+		}
+
+		orders := customer.History()
+		if len(orders) == 0 {
+			report(SeverityWarning, path+".basket", RuleEmptyBasket, "customer %s has no orders", customer.ID)
+		}
+
+		legacy := customer.legacy()
+		for o, basket := range orders {
+			basketPath := path + ".basket"
+			if !legacy {
+				basketPath = fmt.Sprintf("%s.orders[%d]", path, o)
+			}
+
+			if basket.ID == "" {
+				report(SeverityError, basketPath+".id", RuleMissingID, "basket has no id")
+			} else if first, ok := basketIDs[basket.ID]; ok {
+				report(SeverityError, basketPath+".id", RuleDuplicateBasketID, "basket id %s already used at [%d]", basket.ID, first)
+			} else {
+				basketIDs[basket.ID] = i
+			}
+
+			if len(basket.Products) == 0 {
+				report(SeverityWarning, basketPath+".products", RuleEmptyBasket, "basket %s has no products", basket.ID)
+			}
+
+			productLines := make(map[string]int)
+
+			for j, product := range basket.Products {
+				productPath := fmt.Sprintf("%s.products[%d]", basketPath, j)
+
+				if product.ID == "" {
+					report(SeverityError, productPath+".id", RuleMissingID, "product has no id")
+				} else if first, ok := productLines[product.ID]; ok {
+					report(SeverityWarning, productPath+".id", RuleDuplicateProductLine, "product %s already listed at products[%d]", product.ID, first)
+				} else {
+					productLines[product.ID] = j
+				}
+
+				if product.Quantity <= 0 {
+					report(SeverityError, productPath+".quantity", RuleNonPositiveQuantity, "quantity %d must be positive", product.Quantity)
+				}
+				if product.Price.IsNegative() {
+					report(SeverityError, productPath+".price", RuleNegativePrice, "price %s is negative", product.Price)
+				}
+			}
+
+			if sum := basket.ProductsTotal(); sum.Cmp(basket.Total) != 0 {
+				report(SeverityError, basketPath+".total", RuleBasketTotalMismatch, "total %s does not match sum of price*quantity %s", basket.Total, sum)
+			}
+		}
+
+		if total := customer.Basket.Total; customer.Cash.Cmp(total) < 0 {
+			report(SeverityError, path+".cash", RuleInsufficientCash, "cash %s does not cover basket total %s", customer.Cash, total)
+		}
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any issue has error severity.
+func HasErrors(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"fmt"
+
+	"ExamFolder/store"
+)
+
+// Class is an ABC class: A items drive most of the value, C items little.
+type Class string
+
+const (
+	ClassA Class = "A"
+	ClassB Class = "B"
+	ClassC Class = "C"
+)
+
+// Thresholds are the cumulative shares of value at which class A and class
+// B end, e.g. 0.8 and 0.95.
+type Thresholds struct {
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+}
+
+// DefaultThresholds is the usual 80/95 split.
+func DefaultThresholds() Thresholds {
+	return Thresholds{A: 0.8, B: 0.95}
+}
+
+// Validate reports thresholds that do not satisfy 0 < A < B <= 1.
+func (t Thresholds) Validate() error {
+	if t.A <= 0 || t.A >= t.B || t.B > 1 {
+		return fmt.Errorf("task: ABC thresholds need 0 < A < B <= 1, got A %v and B %v", t.A, t.B)
+	}
+	return nil
+}
+
+// classify returns the class of an item whose predecessors in the ranking
+// add up to before. The item that crosses a threshold still belongs to the
+// class below it.
+func (t Thresholds) classify(before float64) Class {
+	switch {
+	case before < t.A:
+		return ClassA
+	case before < t.B:
+		return ClassB
+	default:
+		return ClassC
+	}
+}
+
+// ABCItem is one ranked product or customer. Value is the measure it was
+// ranked by: revenue or basket total in minor units, or units sold.
+type ABCItem struct {
+	Rank       int     `json:"rank"`
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Value      int64   `json:"value"`
+	Share      float64 `json:"share"`
+	Cumulative float64 `json:"cumulative"`
+	Class      Class   `json:"class"`
+}
+
+// ABCClass summarizes one class.
+type ABCClass struct {
+	Class     Class   `json:"class"`
+	Items     int     `json:"items"`
+	ItemShare float64 `json:"item_share"`
+	Value     int64   `json:"value"`
+	Share     float64 `json:"share"`
+}
+
+// abc ranks items by value with TopN's tie-breaking and assigns classes.
+func abc[T any](items []T, value func(T) int64, key func(T) (string, string), t Thresholds) []ABCItem {
+	ranked := TopN(items, 0, value, key)
+
+	var total int64
+	for _, item := range ranked {
+		total += value(item)
+	}
+
+	result := make([]ABCItem, len(ranked))
+	var running int64
+	for i, item := range ranked {
+		id, name := key(item)
+		v := value(item)
+
+		var share, before, cumulative float64
+		if total != 0 {
+			share = float64(v) / float64(total)
+			before = float64(running) / float64(total)
+			cumulative = float64(running+v) / float64(total)
+		}
+		running += v
+
+		result[i] = ABCItem{
+			Rank:       i + 1,
+			ID:         id,
+			Name:       name,
+			Value:      v,
+			Share:      share,
+			Cumulative: cumulative,
+			Class:      t.classify(before),
+		}
+	}
+	return result
+}
+
+// ProductsABCByRevenue classifies product IDs by Price*Quantity revenue.
+func ProductsABCByRevenue(customers []store.Customer, t Thresholds) []ABCItem {
+	return abc(ProductRevenues(customers), func(p ProductRevenue) int64 {
+		return p.Revenue.Amount
+	}, productRevenueKey, t)
+}
+
+// ProductsABCByUnits classifies product IDs by units sold.
+func ProductsABCByUnits(customers []store.Customer, t Thresholds) []ABCItem {
+	return abc(ProductRevenues(customers), func(p ProductRevenue) int64 {
+		return int64(p.Units)
+	}, productRevenueKey, t)
+}
+
+// CustomersABC classifies customers by Basket.Total.
+func CustomersABC(customers []store.Customer, t Thresholds) []ABCItem {
+	return abc(customers, customerSpent, customerKey, t)
+}
+
+// ABCClasses summarizes classified items, always listing A, B and C.
+func ABCClasses(items []ABCItem) []ABCClass {
+	classes := []ABCClass{{Class: ClassA}, {Class: ClassB}, {Class: ClassC}}
+	index := map[Class]int{ClassA: 0, ClassB: 1, ClassC: 2}
+
+	var total int64
+	for _, item := range items {
+		c := &classes[index[item.Class]]
+		c.Items++
+		c.Value += item.Value
+		total += item.Value
+	}
+
+	for i := range classes {
+		if len(items) > 0 {
+			classes[i].ItemShare = float64(classes[i].Items) / float64(len(items))
+		}
+		if total != 0 {
+			classes[i].Share = float64(classes[i].Value) / float64(total)
+		}
+	}
+	return classes
+}
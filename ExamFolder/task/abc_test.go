@@ -0,0 +1,106 @@
+package task
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func spender(id string, total int64) store.Customer {
+	return store.Customer{ID: id, Basket: store.Basket{ID: "B" + id, Total: store.Major(total, "")}}
+}
+
+func classes(items []ABCItem) string {
+	var s string
+	for _, item := range items {
+		s += item.ID + ":" + string(item.Class) + " "
+	}
+	return s
+}
+
+func TestCustomersABC(t *testing.T) {
+	tests := []struct {
+		name      string
+		customers []store.Customer
+		want      string
+	}{
+		// C2 ends exactly on 80% and stays A; C3 starts on 80% and is B.
+		{"exact boundaries", []store.Customer{spender("C4", 5), spender("C1", 50), spender("C3", 15), spender("C2", 30)}, "C1:A C2:A C3:B C4:C "},
+		// C2 crosses 80% (60% to 90%) and still belongs to A.
+		{"crossing item stays below", []store.Customer{spender("C1", 60), spender("C2", 30), spender("C3", 10)}, "C1:A C2:A C3:B "},
+		// A single dominant item leaves everyone else in C.
+		{"dominant item", []store.Customer{spender("C1", 96), spender("C2", 2), spender("C3", 2)}, "C1:A C2:C C3:C "},
+		// Ties rank by ID, even when the boundary falls between them.
+		{"ties by ID", []store.Customer{spender("C2", 10), spender("C4", 5), spender("C1", 10), spender("C3", 75)}, "C3:A C1:A C2:B C4:C "},
+		{"all zero", []store.Customer{spender("C1", 0), spender("C2", 0)}, "C1:A C2:A "},
+	}
+
+	for _, test := range tests {
+		if got := classes(CustomersABC(test.customers, DefaultThresholds())); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestABCShares(t *testing.T) {
+	items := CustomersABC([]store.Customer{spender("C1", 50), spender("C2", 30), spender("C3", 15), spender("C4", 5)}, DefaultThresholds())
+
+	cumulative := []float64{0.5, 0.8, 0.95, 1}
+	for i, item := range items {
+		if item.Rank != i+1 || math.Abs(item.Cumulative-cumulative[i]) > 1e-9 {
+			t.Errorf("item %d = %+v, want rank %d at %v", i, item, i+1, cumulative[i])
+		}
+	}
+	if items[1].Value != store.Major(30, "").Amount || math.Abs(items[1].Share-0.3) > 1e-9 {
+		t.Errorf("C2 = %+v, want 30.00 at 30%%", items[1])
+	}
+
+	want := []ABCClass{
+		{Class: ClassA, Items: 2, ItemShare: 0.5, Value: 8000, Share: 0.8},
+		{Class: ClassB, Items: 1, ItemShare: 0.25, Value: 1500, Share: 0.15},
+		{Class: ClassC, Items: 1, ItemShare: 0.25, Value: 500, Share: 0.05},
+	}
+	got := ABCClasses(items)
+	for i := range want {
+		if got[i].Class != want[i].Class || got[i].Items != want[i].Items || got[i].Value != want[i].Value ||
+			math.Abs(got[i].ItemShare-want[i].ItemShare) > 1e-9 || math.Abs(got[i].Share-want[i].Share) > 1e-9 {
+			t.Errorf("class %s = %+v, want %+v", want[i].Class, got[i], want[i])
+		}
+	}
+
+	if got := ABCClasses(nil); len(got) != 3 || got[0].Items != 0 || got[0].Share != 0 {
+		t.Errorf("ABCClasses(nil) = %+v, want three empty classes", got)
+	}
+}
+
+func TestProductsABC(t *testing.T) {
+	basket := store.Basket{ID: "B1", Products: []store.Product{
+		{ID: "P1", Name: "Laptop", Price: store.Major(90, ""), Quantity: 1},
+		{ID: "P2", Name: "Pen", Price: store.Major(1, ""), Quantity: 10},
+	}}
+	customers := []store.Customer{{ID: "C1", Basket: basket}}
+	thresholds := Thresholds{A: 0.5, B: 0.9}
+
+	if got := classes(ProductsABCByRevenue(customers, thresholds)); got != "P1:A P2:C " {
+		t.Errorf("by revenue: %q, want P1 in A (90 of 100) and P2 in C", got)
+	}
+	if got := classes(ProductsABCByUnits(customers, thresholds)); got != "P2:A P1:C " {
+		t.Errorf("by units: %q, want P2 in A (10 of 11 units) and P1 in C", got)
+	}
+}
+
+func TestThresholdsValidate(t *testing.T) {
+	for _, bad := range []Thresholds{{0, 0.9}, {0.9, 0.9}, {0.95, 0.8}, {0.8, 1.1}} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("%+v validated", bad)
+		}
+	}
+	if err := (Thresholds{A: 0.5, B: 1}).Validate(); err != nil {
+		t.Errorf("A 0.5, B 1: %v", err)
+	}
+	if !reflect.DeepEqual(DefaultThresholds(), Thresholds{A: 0.8, B: 0.95}) {
+		t.Errorf("DefaultThresholds = %+v", DefaultThresholds())
+	}
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"sort"
+
+	"ExamFolder/store"
+)
+
+// TopN returns the n items with the highest score, best first. Ties are
+// broken by ID, then by name, both ascending, so the order never depends on
+// input or map order. n <= 0 returns the full ranking.
+func TopN[T any](items []T, n int, score func(T) int64, key func(T) (id, name string)) []T {
+	return rank(items, n, score, key, true)
+}
+
+// BottomN returns the n items with the lowest score, lowest first, with the
+// same tie-breaking as TopN.
+func BottomN[T any](items []T, n int, score func(T) int64, key func(T) (id, name string)) []T {
+	return rank(items, n, score, key, false)
+}
+
+func rank[T any](items []T, n int, score func(T) int64, key func(T) (string, string), descending bool) []T {
+	ranked := make([]T, len(items))
+	copy(ranked, items)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranksBefore(ranked[i], ranked[j], score, key, descending)
+	})
+
+	if n > 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+func ranksBefore[T any](a, b T, score func(T) int64, key func(T) (string, string), descending bool) bool {
+	aScore, bScore := score(a), score(b)
+	if aScore != bScore {
+		if descending {
+			return aScore > bScore
+		}
+		return aScore < bScore
+	}
+
+	aID, aName := key(a)
+	bID, bName := key(b)
+	if aID != bID {
+		return aID < bID
+	}
+	return aName < bName
+}
+
+func customerKey(customer store.Customer) (string, string) {
+	return customer.ID, customer.FirstName + " " + customer.LastName
+}
+
+func customerSpent(customer store.Customer) int64 {
+	return customer.Basket.Total.Amount
+}
+
+func productRevenueKey(product ProductRevenue) (string, string) {
+	return product.ID, product.Name
+}
+
+// TopCustomers ranks customers by basket total, highest first.
+func TopCustomers(customers []store.Customer, n int) []store.Customer {
+	return TopN(customers, n, customerSpent, customerKey)
+}
+
+// BottomCustomers ranks customers by basket total, lowest first.
+func BottomCustomers(customers []store.Customer, n int) []store.Customer {
+	return BottomN(customers, n, customerSpent, customerKey)
+}
+
+// TopProductsByRevenue ranks product IDs by Price*Quantity revenue.
+func TopProductsByRevenue(customers []store.Customer, n int) []ProductRevenue {
+	return TopN(ProductRevenues(customers), n, func(p ProductRevenue) int64 {
+		return p.Revenue.Amount
+	}, productRevenueKey)
+}
+
+// TopProductsByUnits ranks product IDs by units sold.
+func TopProductsByUnits(customers []store.Customer, n int) []ProductRevenue {
+	return TopN(ProductRevenues(customers), n, func(p ProductRevenue) int64 {
+		return int64(p.Units)
+	}, productRevenueKey)
+}
+
+// BottomProductsByUnits ranks product IDs by units sold, fewest first.
+func BottomProductsByUnits(customers []store.Customer, n int) []ProductRevenue {
+	return BottomN(ProductRevenues(customers), n, func(p ProductRevenue) int64 {
+		return int64(p.Units)
+	}, productRevenueKey)
+}
+
+// TopCategoriesByRevenue ranks categories by Price*Quantity revenue.
+func TopCategoriesByRevenue(customers []store.Customer, n int) []CategoryRevenue {
+	return AggregateCustomers(customers).TopCategoriesByRevenue(n)
+}
+
+// TopCategoriesByUnits ranks categories by units sold.
+func TopCategoriesByUnits(customers []store.Customer, n int) []CategoryQuantity {
+	return AggregateCustomers(customers).TopCategoriesByUnits(n)
+}
+
+// TopCategoriesByRevenue ranks the aggregated categories by revenue.
+func (a *Aggregate) TopCategoriesByRevenue(n int) []CategoryRevenue {
+	return TopN(a.CategoryRevenues(), n, func(c CategoryRevenue) int64 {
+		return c.Revenue.Amount
+	}, func(c CategoryRevenue) (string, string) {
+		return c.Category, c.Category
+	})
+}
+
+// TopCategoriesByUnits ranks the aggregated categories by units sold.
+func (a *Aggregate) TopCategoriesByUnits(n int) []CategoryQuantity {
+	return TopN(a.CategoryQuantities(), n, func(c CategoryQuantity) int64 {
+		return int64(c.Units)
+	}, func(c CategoryQuantity) (string, string) {
+		return c.Category, c.Category
+	})
+}
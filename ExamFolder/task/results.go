@@ -0,0 +1,331 @@
+package task
+
+import (
+	"sort"
+
+	"ExamFolder/catalog"
+	"ExamFolder/stats"
+	"ExamFolder/store"
+)
+
+// CustomerTotals is the result of Task 1.
+type CustomerTotals struct {
+	Customers  int         `json:"customers"`
+	TotalCash  store.Money `json:"total_cash"`
+	TotalSpent store.Money `json:"total_spent"`
+}
+
+// CategoryQuantity is the number of units sold in one category.
+type CategoryQuantity struct {
+	Category string `json:"category"`
+	Units    int    `json:"units"`
+}
+
+// CategoryRevenue is the Price*Quantity revenue of one category.
+type CategoryRevenue struct {
+	Category string      `json:"category"`
+	Revenue  store.Money `json:"revenue"`
+}
+
+// AverageQuantity is the result of Task 8. Stats describes the product
+// lines per customer.
+type AverageQuantity struct {
+	Products  int           `json:"products"`
+	Customers int           `json:"customers"`
+	Average   float64       `json:"average"`
+	Stats     stats.Summary `json:"stats"`
+}
+
+// CustomerProductCount is the result of Task 9: the customer with the most
+// product lines and the number of lines over all customers.
+type CustomerProductCount struct {
+	Customer      store.Customer `json:"customer"`
+	TotalProducts int            `json:"total_products"`
+}
+
+// ProductCount is how many basket lines a product ID appears on.
+type ProductCount = catalog.ProductCount
+
+// AverageSpending is the result of Task 11.
+type AverageSpending struct {
+	Average    store.Money    `json:"average"`
+	TopSpender store.Customer `json:"top_spender"`
+	Stats      stats.Summary  `json:"stats"`
+}
+
+// CustomerProduct pairs a customer with one of their products. Product is
+// empty when the customer has none.
+type CustomerProduct struct {
+	Customer store.Customer `json:"customer"`
+	Product  store.Product  `json:"product"`
+}
+
+// CustomerCategory pairs a customer with a category and what they spent in
+// it. Category is empty when the customer bought nothing.
+type CustomerCategory struct {
+	Customer store.Customer `json:"customer"`
+	Category string         `json:"category"`
+	Spent    store.Money    `json:"spent"`
+}
+
+// ProductQuantity is the number of units sold of one product ID.
+type ProductQuantity = catalog.ProductQuantity
+
+// ProductNameCount is how many basket lines carry a product name.
+type ProductNameCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// CustomerAverage pairs a customer with an average amount.
+type CustomerAverage struct {
+	Customer store.Customer `json:"customer"`
+	Average  store.Money    `json:"average"`
+}
+
+// ProductRevenue is the units and Price*Quantity revenue of one product ID.
+type ProductRevenue struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Category string      `json:"category"`
+	Units    int         `json:"units"`
+	Revenue  store.Money `json:"revenue"`
+}
+
+// SoldQuantity is the result of Task 15.
+type SoldQuantity = catalog.SoldQuantity
+
+// Task 1: Total cash and total spent over all customers.
+func CalculateCustomerTotals(customers []store.Customer) CustomerTotals {
+	aggregate := AggregateCustomers(customers)
+	return CustomerTotals{
+		Customers:  aggregate.Customers,
+		TotalCash:  aggregate.TotalCash,
+		TotalSpent: aggregate.TotalSpent,
+	}
+}
+
+// Task 6: Units sold per category, ordered by category name.
+func CategoryQuantities(customers []store.Customer) []CategoryQuantity {
+	return AggregateCustomers(customers).CategoryQuantities()
+}
+
+// Task 8: Average number of product lines per customer.
+func CalculateAverageQuantitySold(customers []store.Customer) (AverageQuantity, bool) {
+	if len(customers) == 0 {
+		return AverageQuantity{}, false
+	}
+
+	summary := stats.Describe(stats.Project(customers, func(c store.Customer) float64 {
+		return float64(len(c.Basket.Products))
+	}))
+	return AverageQuantity{
+		Products:  int(summary.Sum),
+		Customers: summary.Count,
+		Average:   summary.Mean,
+		Stats:     summary,
+	}, true
+}
+
+// Task 9: The customer with the most product lines.
+func TopCustomerByProductQuantity(customers []store.Customer) (CustomerProductCount, bool) {
+	if len(customers) == 0 {
+		return CustomerProductCount{}, false
+	}
+
+	topCustomer := customers[0]
+	totalQuantity := 0
+
+	for _, customer := range customers {
+		quantity := len(customer.Basket.Products)
+		totalQuantity += quantity
+
+		if quantity > len(topCustomer.Basket.Products) {
+			topCustomer = customer
+		}
+	}
+
+	return CustomerProductCount{Customer: topCustomer, TotalProducts: totalQuantity}, true
+}
+
+// Task 10: The product ID that appears on the most basket lines, as
+// defined in the catalog.
+func MostSoldProduct(allProducts []store.Product) (ProductCount, bool) {
+	return catalog.FromProducts(allProducts).MostSoldProduct(allProducts)
+}
+
+// Task 11: Mean basket total and the top spender.
+func CalculateAverageSpending(customers []store.Customer) (AverageSpending, bool) {
+	if len(customers) == 0 {
+		return AverageSpending{}, false
+	}
+
+	aggregate := AggregateCustomers(customers)
+	summary := stats.Describe(stats.Project(customers, func(c store.Customer) float64 {
+		return c.Basket.Total.Float64()
+	}))
+	return AverageSpending{
+		Average:    aggregate.TotalSpent.Div(summary.Count),
+		TopSpender: aggregate.TopSpender,
+		Stats:      summary,
+	}, true
+}
+
+// Task 12: Revenue per category, ordered by category name.
+func CategoryRevenues(customers []store.Customer) []CategoryRevenue {
+	return AggregateCustomers(customers).CategoryRevenues()
+}
+
+// Task 12: The category with the highest revenue.
+func MostProfitableCategory(customers []store.Customer) (CategoryRevenue, bool) {
+	return AggregateCustomers(customers).MostProfitableCategory()
+}
+
+// Task 13: Each customer's most expensive product.
+func MostExpensivePurchaseByCustomer(customers []store.Customer) []CustomerProduct {
+	purchases := make([]CustomerProduct, 0, len(customers))
+	for _, customer := range customers {
+		purchases = append(purchases, CustomerProduct{
+			Customer: customer,
+			Product:  FindMostExpensiveProduct(customer.Basket.Products),
+		})
+	}
+	return purchases
+}
+
+// Task 14: The category each customer spent the most in.
+func MostExpensiveCategoryByCustomer(customers []store.Customer) []CustomerCategory {
+	categories := make([]CustomerCategory, 0, len(customers))
+
+	for _, customer := range customers {
+		result := CustomerCategory{Customer: customer}
+
+		for _, revenue := range CategoryRevenues([]store.Customer{customer}) {
+			if revenue.Revenue.Cmp(result.Spent) > 0 {
+				result.Category = revenue.Category
+				result.Spent = revenue.Revenue
+			}
+		}
+
+		categories = append(categories, result)
+	}
+
+	return categories
+}
+
+// Task 15: Units sold per product ID, in order of first appearance, named
+// as in the catalog.
+func TotalSoldQuantity(products []store.Product) SoldQuantity {
+	return catalog.FromProducts(products).TotalSoldQuantity(products)
+}
+
+// Task 4 (single-page variant): Mean unit price over all product lines.
+func CalculateAveragePrice(allProducts []store.Product) (store.Money, bool) {
+	if len(allProducts) == 0 {
+		return store.Money{}, false
+	}
+
+	var total store.Money
+	for _, product := range allProducts {
+		total = total.Add(product.Price)
+	}
+
+	return total.Div(len(allProducts)), true
+}
+
+// Task 10 (single-page variant): The product name that appears on the most
+// basket lines, counting lines rather than units.
+func MostFrequentProductName(customers []store.Customer) (ProductNameCount, bool) {
+	var counts []ProductNameCount
+	index := make(map[string]int)
+
+	for _, product := range AllProducts(customers) {
+		i, ok := index[product.Name]
+		if !ok {
+			i = len(counts)
+			index[product.Name] = i
+			counts = append(counts, ProductNameCount{Name: product.Name})
+		}
+		counts[i].Count++
+	}
+
+	top := TopN(counts, 1, func(c ProductNameCount) int64 {
+		return int64(c.Count)
+	}, func(c ProductNameCount) (string, string) {
+		return c.Name, c.Name
+	})
+	if len(top) == 0 {
+		return ProductNameCount{}, false
+	}
+	return top[0], true
+}
+
+// Task 11 (single-page variant): The customer whose basket lines have the
+// highest average Price*Quantity. Customers with empty baskets are skipped.
+func HighestAverageLineSpending(customers []store.Customer) (CustomerAverage, bool) {
+	var averages []CustomerAverage
+	for _, customer := range customers {
+		if len(customer.Basket.Products) == 0 {
+			continue
+		}
+		averages = append(averages, CustomerAverage{
+			Customer: customer,
+			Average:  customer.Basket.ProductsTotal().Div(len(customer.Basket.Products)),
+		})
+	}
+
+	top := TopN(averages, 1, func(c CustomerAverage) int64 {
+		return c.Average.Amount
+	}, func(c CustomerAverage) (string, string) {
+		return customerKey(c.Customer)
+	})
+	if len(top) == 0 {
+		return CustomerAverage{}, false
+	}
+	return top[0], true
+}
+
+// ProductRevenues sums units and revenue per product ID, in order of first
+// appearance.
+func ProductRevenues(customers []store.Customer) []ProductRevenue {
+	var revenues []ProductRevenue
+	index := make(map[string]int)
+
+	for _, product := range AllProducts(customers) {
+		i, ok := index[product.ID]
+		if !ok {
+			i = len(revenues)
+			index[product.ID] = i
+			revenues = append(revenues, ProductRevenue{ID: product.ID, Name: product.Name, Category: product.Category})
+		}
+
+		revenues[i].Units += product.Quantity
+		revenues[i].Revenue = revenues[i].Revenue.Add(product.LineTotal())
+	}
+
+	return revenues
+}
+
+// CategoryQuantities lists units sold per category, ordered by name.
+func (a *Aggregate) CategoryQuantities() []CategoryQuantity {
+	quantities := make([]CategoryQuantity, 0, len(a.CategoryQuantity))
+	for category, units := range a.CategoryQuantity {
+		quantities = append(quantities, CategoryQuantity{Category: category, Units: units})
+	}
+	sort.Slice(quantities, func(i, j int) bool {
+		return quantities[i].Category < quantities[j].Category
+	})
+	return quantities
+}
+
+// CategoryRevenues lists revenue per category, ordered by name.
+func (a *Aggregate) CategoryRevenues() []CategoryRevenue {
+	revenues := make([]CategoryRevenue, 0, len(a.CategoryRevenue))
+	for category, revenue := range a.CategoryRevenue {
+		revenues = append(revenues, CategoryRevenue{Category: category, Revenue: revenue})
+	}
+	sort.Slice(revenues, func(i, j int) bool {
+		return revenues[i].Category < revenues[j].Category
+	})
+	return revenues
+}
@@ -0,0 +1,50 @@
+package task
+
+import (
+	"bytes"
+	"testing"
+
+	"ExamFolder/store"
+)
+
+func customerWithLines(id string, total int64, lines int) store.Customer {
+	basket := store.Basket{ID: "B" + id}
+	for i := 0; i < lines; i++ {
+		basket.Products = append(basket.Products, store.Product{ID: "P", Price: store.Major(total, ""), Quantity: 1})
+	}
+	basket.Total = store.Major(total*int64(lines), "")
+	return store.Customer{ID: id, Basket: basket}
+}
+
+func TestAveragesOfNoCustomers(t *testing.T) {
+	if got, ok := CalculateAverageQuantitySold(nil); ok {
+		t.Errorf("CalculateAverageQuantitySold(nil) = %+v, true; want false", got)
+	}
+	if got, ok := CalculateAverageSpending(nil); ok {
+		t.Errorf("CalculateAverageSpending(nil) = %+v, true; want false", got)
+	}
+
+	var out bytes.Buffer
+	CalculateAndPrintAverageQuantitySold(&out, nil)
+	CalculateAndPrintAverageSpending(&out, nil)
+	if got := out.String(); got != "Customer not found.\nCustomer not found.\n" {
+		t.Errorf("printed %q for no customers", got)
+	}
+}
+
+func TestAverages(t *testing.T) {
+	customers := []store.Customer{
+		customerWithLines("C1", 10, 3),
+		customerWithLines("C2", 5, 0),
+	}
+
+	quantity, ok := CalculateAverageQuantitySold(customers)
+	if !ok || quantity.Products != 3 || quantity.Customers != 2 || quantity.Average != 1.5 || quantity.Stats.Median != 1.5 {
+		t.Errorf("CalculateAverageQuantitySold = %+v, %v; want 3 lines over 2 customers", quantity, ok)
+	}
+
+	spending, ok := CalculateAverageSpending(customers)
+	if !ok || spending.Average != store.Major(15, "") || spending.TopSpender.ID != "C1" || spending.Stats.Max != 30 {
+		t.Errorf("CalculateAverageSpending = %+v, %v; want 15.00 with C1 on top", spending, ok)
+	}
+}
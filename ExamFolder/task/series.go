@@ -0,0 +1,251 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"ExamFolder/store"
+)
+
+// Granularity is the width of a time-series bucket.
+type Granularity string
+
+const (
+	Day   Granularity = "day"
+	Week  Granularity = "week"
+	Month Granularity = "month"
+)
+
+// DefaultMovingWindow is the number of periods in a moving average when no
+// other is asked for.
+const DefaultMovingWindow = 3
+
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(s); g {
+	case Day, Week, Month:
+		return g, nil
+	default:
+		return "", fmt.Errorf("task: unknown granularity %q (want day, week or month)", s)
+	}
+}
+
+// Start returns the start of the bucket holding t, in UTC. Weeks start on
+// Monday.
+func (g Granularity) Start(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch g {
+	case Week:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// Next returns the start of the bucket after the one starting at start.
+func (g Granularity) Next(start time.Time) time.Time {
+	switch g {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Label names the bucket starting at start: 2024-03-15, 2024-W11 or 2024-03.
+func (g Granularity) Label(start time.Time) string {
+	switch g {
+	case Week:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Month:
+		return start.Format("2006-01")
+	default:
+		return start.Format(time.DateOnly)
+	}
+}
+
+// Sales is what was sold in one bucket, or in all undated baskets.
+type Sales struct {
+	Revenue store.Money `json:"revenue"`
+	Units   int         `json:"units"`
+	Baskets int         `json:"baskets"`
+}
+
+// Period is one bucket of a series. Growth is the revenue change against
+// the previous period as a fraction, nil for the first period or when the
+// previous one had no revenue. The moving averages are trailing, over up to
+// TimeSeries.MovingWindow periods ending with this one.
+type Period struct {
+	Label         string      `json:"label"`
+	Start         time.Time   `json:"start"`
+	Growth        *float64    `json:"growth"`
+	MovingRevenue store.Money `json:"moving_revenue"`
+	MovingUnits   float64     `json:"moving_units"`
+	Sales
+}
+
+// Series is the periods of one category or product. Key is the category
+// name or product ID.
+type Series struct {
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Periods []Period `json:"periods"`
+}
+
+// TimeSeries buckets dated baskets by Granularity. Every series covers the
+// same contiguous run of periods, from the first dated basket to the last,
+// with empty periods kept at zero. Undated baskets are only summed in
+// Undated.
+type TimeSeries struct {
+	Granularity  Granularity `json:"granularity"`
+	MovingWindow int         `json:"moving_window"`
+	Total        []Period    `json:"total"`
+	Categories   []Series    `json:"categories"`
+	Products     []Series    `json:"products"`
+	Undated      Sales       `json:"undated"`
+}
+
+// seriesBuilder accumulates sales per bucket start for one key.
+type seriesBuilder struct {
+	key     string
+	name    string
+	buckets map[time.Time]*Sales
+}
+
+func (b *seriesBuilder) add(start time.Time, revenue store.Money, units int, newBasket bool) {
+	sales, ok := b.buckets[start]
+	if !ok {
+		sales = &Sales{}
+		b.buckets[start] = sales
+	}
+	sales.Revenue = sales.Revenue.Add(revenue)
+	sales.Units += units
+	if newBasket {
+		sales.Baskets++
+	}
+}
+
+// SalesSeries buckets every order of every customer. window is the length
+// of the moving averages; values below 1 use DefaultMovingWindow.
+func SalesSeries(customers []store.Customer, g Granularity, window int) TimeSeries {
+	if window < 1 {
+		window = DefaultMovingWindow
+	}
+
+	result := TimeSeries{Granularity: g, MovingWindow: window}
+	total := &seriesBuilder{buckets: make(map[time.Time]*Sales)}
+	categories := make(map[string]*seriesBuilder)
+	products := make(map[string]*seriesBuilder)
+
+	builder := func(builders map[string]*seriesBuilder, key, name string) *seriesBuilder {
+		b, ok := builders[key]
+		if !ok {
+			b = &seriesBuilder{key: key, name: name, buckets: make(map[time.Time]*Sales)}
+			builders[key] = b
+		}
+		return b
+	}
+
+	var first, last time.Time
+	for _, customer := range customers {
+		for _, order := range customer.History() {
+			if order.PurchasedAt == nil {
+				result.Undated.Revenue = result.Undated.Revenue.Add(order.ProductsTotal())
+				result.Undated.Units += basketUnits(order)
+				result.Undated.Baskets++
+				continue
+			}
+
+			start := g.Start(*order.PurchasedAt)
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if start.After(last) {
+				last = start
+			}
+
+			total.add(start, order.ProductsTotal(), basketUnits(order), true)
+
+			seen := make(map[string]bool)
+			for _, product := range order.Products {
+				category := builder(categories, product.Category, product.Category)
+				category.add(start, product.LineTotal(), product.Quantity, !seen["c:"+product.Category])
+				seen["c:"+product.Category] = true
+
+				item := builder(products, product.ID, product.Name)
+				item.add(start, product.LineTotal(), product.Quantity, !seen["p:"+product.ID])
+				seen["p:"+product.ID] = true
+			}
+		}
+	}
+
+	if first.IsZero() {
+		return result
+	}
+
+	var starts []time.Time
+	for start := first; !start.After(last); start = g.Next(start) {
+		starts = append(starts, start)
+	}
+
+	result.Total = periods(total, g, starts, window)
+	result.Categories = sortedSeries(categories, g, starts, window)
+	result.Products = sortedSeries(products, g, starts, window)
+	return result
+}
+
+func basketUnits(basket store.Basket) int {
+	units := 0
+	for _, product := range basket.Products {
+		units += product.Quantity
+	}
+	return units
+}
+
+func sortedSeries(builders map[string]*seriesBuilder, g Granularity, starts []time.Time, window int) []Series {
+	series := make([]Series, 0, len(builders))
+	for _, b := range builders {
+		series = append(series, Series{Key: b.key, Name: b.name, Periods: periods(b, g, starts, window)})
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return series[i].Key < series[j].Key
+	})
+	return series
+}
+
+func periods(b *seriesBuilder, g Granularity, starts []time.Time, window int) []Period {
+	result := make([]Period, len(starts))
+	for i, start := range starts {
+		period := Period{Label: g.Label(start), Start: start}
+		if sales, ok := b.buckets[start]; ok {
+			period.Sales = *sales
+		}
+
+		if i > 0 && result[i-1].Revenue.Amount != 0 {
+			previous := result[i-1].Revenue
+			growth := float64(period.Revenue.Amount-previous.Amount) / float64(previous.Amount)
+			period.Growth = &growth
+		}
+
+		from := max(i-window+1, 0)
+		var revenue store.Money
+		units := 0
+		for _, p := range result[from:i] {
+			revenue = revenue.Add(p.Revenue)
+			units += p.Units
+		}
+		revenue = revenue.Add(period.Revenue)
+		units += period.Units
+		period.MovingRevenue = revenue.Div(i - from + 1)
+		period.MovingUnits = float64(units) / float64(i-from+1)
+
+		result[i] = period
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package task
+
+import (
+	"context"
+
+	"ExamFolder/store"
+)
+
+// Aggregate keeps the running totals behind the dataset-wide tasks so they
+// can be fed one customer at a time from a store.Streamer.
+type Aggregate struct {
+	Customers        int
+	TotalCash        store.Money
+	TotalSpent       store.Money
+	CategoryQuantity map[string]int
+	CategoryRevenue  map[string]store.Money
+	TopSpender       store.Customer
+	LowestSpender    store.Customer
+}
+
+func NewAggregate() *Aggregate {
+	return &Aggregate{
+		CategoryQuantity: make(map[string]int),
+		CategoryRevenue:  make(map[string]store.Money),
+	}
+}
+
+// Add folds one customer into the aggregate. Its signature matches the
+// store.Streamer callback.
+func (a *Aggregate) Add(customer store.Customer) error {
+	if a.Customers == 0 || ranksBefore(customer, a.TopSpender, customerSpent, customerKey, true) {
+		a.TopSpender = customer
+	}
+	if a.Customers == 0 || ranksBefore(customer, a.LowestSpender, customerSpent, customerKey, false) {
+		a.LowestSpender = customer
+	}
+
+	a.Customers++
+	a.TotalCash = a.TotalCash.Add(customer.Cash)
+	a.TotalSpent = a.TotalSpent.Add(customer.Basket.Total)
+
+	for _, product := range customer.Basket.Products {
+		a.CategoryQuantity[product.Category] += product.Quantity
+		a.CategoryRevenue[product.Category] = a.CategoryRevenue[product.Category].Add(product.LineTotal())
+	}
+
+	return nil
+}
+
+// AggregateCustomers builds an Aggregate from customers already in memory.
+func AggregateCustomers(customers []store.Customer) *Aggregate {
+	aggregate := NewAggregate()
+	for _, customer := range customers {
+		aggregate.Add(customer)
+	}
+	return aggregate
+}
+
+// AggregateStream builds an Aggregate without materializing the dataset.
+func AggregateStream(ctx context.Context, source store.Streamer) (*Aggregate, error) {
+	aggregate := NewAggregate()
+	if err := source.Stream(ctx, aggregate.Add); err != nil {
+		return nil, err
+	}
+	return aggregate, nil
+}
+
+// BestSellingCategory returns the category with the most units sold.
+func (a *Aggregate) BestSellingCategory() string {
+	top := a.TopCategoriesByUnits(1)
+	if len(top) == 0 {
+		return ""
+	}
+	return top[0].Category
+}
+
+// MostProfitableCategory returns the category with the highest revenue.
+func (a *Aggregate) MostProfitableCategory() (CategoryRevenue, bool) {
+	top := a.TopCategoriesByRevenue(1)
+	if len(top) == 0 {
+		return CategoryRevenue{}, false
+	}
+	return top[0], true
+}
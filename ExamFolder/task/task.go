@@ -2,39 +2,25 @@ package task
 
 import (
 	"fmt"
+	"io"
+
 	"ExamFolder/store"
 )
 
 // Task 1: Print details of all customers, including their total cash and total spent.
-func PrintCustomerDetails(customers []store.Customer) {
-	totalCash := 0.0
-	totalSpent := 0.0
-
+func PrintCustomerDetails(w io.Writer, customers []store.Customer) {
 	for _, customer := range customers {
-		store.PrintCustomerInfo(customer)
-		totalCash += customer.Cash
-		totalSpent += customer.Basket.Total
+		store.FprintCustomerInfo(w, customer)
 	}
 
-	fmt.Printf("Total Customer Cash: %.2f\n", totalCash)
-	fmt.Printf("Total Amount Spent: %.2f\n", totalSpent)
+	totals := CalculateCustomerTotals(customers)
+	fmt.Fprintf(w, "Total Customer Cash: %s\n", totals.TotalCash)
+	fmt.Fprintf(w, "Total Amount Spent: %s\n", totals.TotalSpent)
 }
 
 // Task 2: Find the customer who spent the most.
 func FindTopSpender(customers []store.Customer) store.Customer {
-	if len(customers) == 0 {
-		return store.Customer{}
-	}
-
-	topSpender := customers[0]
-
-	for _, customer := range customers {
-		if customer.Basket.Total > topSpender.Basket.Total {
-			topSpender = customer
-		}
-	}
-
-	return topSpender
+	return AggregateCustomers(customers).TopSpender
 }
 
 // Task 3: Find the most expensive product among all products.
@@ -46,7 +32,7 @@ func FindMostExpensiveProduct(products []store.Product) store.Product {
 	mostExpensive := products[0]
 
 	for _, product := range products {
-		if product.Price > mostExpensive.Price {
+		if product.Price.Cmp(mostExpensive.Price) > 0 {
 			mostExpensive = product
 		}
 	}
@@ -64,42 +50,13 @@ func AllProducts(customers []store.Customer) []store.Product {
 }
 
 // Task 5: Print details of the customer who spent the least.
-func PrintLowestSpender(customers []store.Customer) {
-	lowestSpender := FindLowestSpender(customers)
-
-	if lowestSpender.ID == "" {
-		fmt.Println("Customer not found.")
-		return
-	}
-
-	fmt.Println("Customer with the least total purchase amount:")
-	store.PrintCustomerInfo(lowestSpender)
+func PrintLowestSpender(w io.Writer, customers []store.Customer) {
+	printLowestSpender(w, AggregateCustomers(customers))
 }
 
 // Task 6: Find the best-selling category among all products.
 func FindBestSellingCategory(customers []store.Customer) string {
-	if len(customers) == 0 {
-		return ""
-	}
-
-	categoryCounts := make(map[string]int)
-
-	for _, customer := range customers {
-		for _, product := range customer.Basket.Products {
-			categoryCounts[product.Category] += product.Quantity
-		}
-	}
-
-	bestSellingCategory := ""
-	maxQuantity := 0
-	for category, quantity := range categoryCounts {
-		if quantity > maxQuantity {
-			bestSellingCategory = category
-			maxQuantity = quantity
-		}
-	}
-
-	return bestSellingCategory
+	return AggregateCustomers(customers).BestSellingCategory()
 }
 
 // Task 7: Find the product sold in maximum and minimum quantity among all customers.
@@ -124,209 +81,151 @@ func FindMinMaxSoldProducts(customers []store.Customer) (store.Product, store.Pr
 }
 
 // Task 8: Calculate and print the average quantity of products sold per customer.
-func CalculateAndPrintAverageQuantitySold(customers []store.Customer) {
-	totalSales := len(customers)
-	totalQuantity := 0
-
-	for _, customer := range customers {
-		totalQuantity += len(customer.Basket.Products)
+func CalculateAndPrintAverageQuantitySold(w io.Writer, customers []store.Customer) {
+	average, ok := CalculateAverageQuantitySold(customers)
+	if !ok {
+		fmt.Fprintln(w, "Customer not found.")
+		return
 	}
 
-	average := float64(totalQuantity) / float64(totalSales)
-	fmt.Printf("Average Product Quantity: %d / %d = %.3f\n", totalQuantity, totalSales, average)
+	fmt.Fprintf(w, "Average Product Quantity: %d / %d = %.3f\n", average.Products, average.Customers, average.Average)
 }
 
 // Task 9: Find the customer who purchased the most number of products.
-func FindTopCustomerByProductQuantity(customers []store.Customer) {
-	if len(customers) == 0 {
-		fmt.Println("Customer not found.")
+func FindTopCustomerByProductQuantity(w io.Writer, customers []store.Customer) {
+	top, ok := TopCustomerByProductQuantity(customers)
+	if !ok {
+		fmt.Fprintln(w, "Customer not found.")
 		return
 	}
 
-	topCustomer := customers[0]
-	totalQuantity := 0
-
-	for _, customer := range customers {
-		quantity := len(customer.Basket.Products)
-		totalQuantity += quantity
-
-		if quantity > len(topCustomer.Basket.Products) {
-			topCustomer = customer
-		}
-	}
-
-	fmt.Println("Customer with the Most Products Purchased:")
-	store.PrintCustomerInfo(topCustomer)
-	fmt.Printf("Total number of products purchased: %d\n", totalQuantity)
+	fmt.Fprintln(w, "Customer with the Most Products Purchased:")
+	store.FprintCustomerInfo(w, top.Customer)
+	fmt.Fprintf(w, "Total number of products purchased: %d\n", top.TotalProducts)
 }
 
 // Task 10: Find the most sold product among all.
-func FindMostSoldProduct(allProducts []store.Product) {
-	if len(allProducts) == 0 {
-		fmt.Println("No sold products found.")
+func FindMostSoldProduct(w io.Writer, allProducts []store.Product) {
+	mostSold, ok := MostSoldProduct(allProducts)
+	if !ok {
+		fmt.Fprintln(w, "No sold products found.")
 		return
 	}
 
-	productCount := make(map[string]int)
-
-	for _, product := range allProducts {
-		productCount[product.ID]++
-	}
-
-	var mostSoldProductID string
-	var maxCount int
-
-	for productID, count := range productCount {
-		if count > maxCount {
-			mostSoldProductID = productID
-			maxCount = count
-		}
-	}
-
-	mostSoldProduct := FindProductByID(allProducts, mostSoldProductID)
-
-	fmt.Println("Most Sold Product among Sold Products:")
-	store.PrintProductInfo(mostSoldProduct)
+	fmt.Fprintln(w, "Most Sold Product among Sold Products:")
+	store.FprintProductInfo(w, mostSold.Product)
 }
 
 // Task 11: Calculate and print the average spending of customers.
-func CalculateAndPrintAverageSpending(customers []store.Customer) {
-	if len(customers) == 0 {
-		fmt.Println("Customer not found.")
+func CalculateAndPrintAverageSpending(w io.Writer, customers []store.Customer) {
+	spending, ok := CalculateAverageSpending(customers)
+	if !ok {
+		fmt.Fprintln(w, "Customer not found.")
 		return
 	}
 
-	totalSpent := 0.0
-	for _, customer := range customers {
-		totalSpent += customer.Basket.Total
-	}
-
-	averageSpent := totalSpent / float64(len(customers))
-	fmt.Printf("Average Total Spending per Customer: %.2f\n", averageSpent)
-
-	topSpender := FindTopSpender(customers)
-	fmt.Println("Top Spending Customer:")
-	store.PrintCustomerInfo(topSpender)
+	fmt.Fprintf(w, "Average Total Spending per Customer: %s\n", spending.Average)
+	fmt.Fprintln(w, "Top Spending Customer:")
+	store.FprintCustomerInfo(w, spending.TopSpender)
 }
 
 // Task 12: Find the most profitable product category among all customers.
-func FindMostProfitableCategory(customers []store.Customer) {
-	if len(customers) == 0 {
-		fmt.Println("Customer not found.")
-		return
-	}
-
-	categoryProfits := make(map[string]float64)
-
-	for _, customer := range customers {
-		for _, product := range customer.Basket.Products {
-			profit := float64(product.Quantity) * product.Price
-			categoryProfits[product.Category] += profit
-		}
-	}
-
-	mostProfitableCategory := ""
-	maxProfit := 0.0
-	for category, profit := range categoryProfits {
-		if profit > maxProfit {
-			mostProfitableCategory = category
-			maxProfit = profit
-		}
-	}
-
-	fmt.Printf("Most Profitable Category: %s (Total Profit: %.2f)\n", mostProfitableCategory, maxProfit)
+func FindMostProfitableCategory(w io.Writer, customers []store.Customer) {
+	printMostProfitableCategory(w, AggregateCustomers(customers))
 }
 
 // Task 13: Find the most expensive purchase made by each customer.
-func FindMostExpensivePurchaseByCustomer(customers []store.Customer) {
+func FindMostExpensivePurchaseByCustomer(w io.Writer, customers []store.Customer) {
 	if len(customers) == 0 {
-		fmt.Println("Customer not found.")
+		fmt.Fprintln(w, "Customer not found.")
 		return
 	}
 
-	for _, customer := range customers {
-		mostExpensiveProduct := FindMostExpensiveProduct(customer.Basket.Products)
+	for _, purchase := range MostExpensivePurchaseByCustomer(customers) {
+		customer := purchase.Customer
 
-		if mostExpensiveProduct.ID != "" {
-			fmt.Printf("%s %s's Most Expensive Purchase:\n", customer.FirstName, customer.LastName)
-			store.PrintProductInfo(mostExpensiveProduct)
+		if purchase.Product.ID != "" {
+			fmt.Fprintf(w, "%s %s's Most Expensive Purchase:\n", customer.FirstName, customer.LastName)
+			store.FprintProductInfo(w, purchase.Product)
 		} else {
-			fmt.Printf("%s %s's Purchase Not Found.\n", customer.FirstName, customer.LastName)
+			fmt.Fprintf(w, "%s %s's Purchase Not Found.\n", customer.FirstName, customer.LastName)
 		}
 	}
 }
 
 // Task 14: Find the category in which each customer spent the most.
-func FindMostExpensiveCategoryByCustomer(customers []store.Customer) {
+func FindMostExpensiveCategoryByCustomer(w io.Writer, customers []store.Customer) {
 	if len(customers) == 0 {
-		fmt.Println("Customer not found.")
+		fmt.Fprintln(w, "Customer not found.")
 		return
 	}
 
-	for _, customer := range customers {
-		categorySpending := make(map[string]float64)
-
-		for _, product := range customer.Basket.Products {
-			categorySpending[product.Category] += product.Price * float64(product.Quantity)
-		}
-
-		mostExpensiveCategory := ""
-		maxSpending := 0.0
-
-		for category, spending := range categorySpending {
-			if spending > maxSpending {
-				mostExpensiveCategory = category
-				maxSpending = spending
-			}
-		}
+	for _, spending := range MostExpensiveCategoryByCustomer(customers) {
+		customer := spending.Customer
 
-		if mostExpensiveCategory != "" {
-			fmt.Printf("%s %s's Most Expensive Category: %s\n", customer.FirstName, customer.LastName, mostExpensiveCategory)
-			fmt.Printf("Total amount spent in this category: %.2f\n", maxSpending)
+		if spending.Category != "" {
+			fmt.Fprintf(w, "%s %s's Most Expensive Category: %s\n", customer.FirstName, customer.LastName, spending.Category)
+			fmt.Fprintf(w, "Total amount spent in this category: %s\n", spending.Spent)
 		} else {
-			fmt.Printf("%s %s's Spending Category Not Found.\n", customer.FirstName, customer.LastName)
+			fmt.Fprintf(w, "%s %s's Spending Category Not Found.\n", customer.FirstName, customer.LastName)
 		}
 	}
 }
 
 // Task 15: Print the total quantity sold for each product and overall.
-func PrintTotalSoldQuantity(products []store.Product) {
+func PrintTotalSoldQuantity(w io.Writer, products []store.Product) {
 	if len(products) == 0 {
-		fmt.Println("Sold products not found.")
+		fmt.Fprintln(w, "Sold products not found.")
 		return
 	}
 
-	productSoldQuantity := make(map[string]int)
-	totalSoldQuantity := 0
+	sold := TotalSoldQuantity(products)
 
-	for _, product := range products {
-		productSoldQuantity[product.Name] += product.Quantity
-		totalSoldQuantity += product.Quantity
+	fmt.Fprintln(w, "Total Quantity Sold for Each Product:")
+	for _, product := range sold.Products {
+		fmt.Fprintf(w, "%s %s: %d units\n", product.ID, product.Name, product.Units)
 	}
 
-	fmt.Println("Total Quantity Sold for Each Product:")
-	for productName, quantity := range productSoldQuantity {
-		fmt.Printf("%s: %d units\n", productName, quantity)
+	fmt.Fprintf(w, "Total Quantity of Sold Products: %d units\n", sold.Total)
+}
+
+// Task 4 (single-page variant): Calculate and print the average price of all products.
+func CalculateAndPrintAveragePrice(w io.Writer, allProducts []store.Product) {
+	average, ok := CalculateAveragePrice(allProducts)
+	if !ok {
+		fmt.Fprintln(w, "No products found.")
+		return
 	}
 
-	fmt.Printf("Total Quantity of Sold Products: %d units\n", totalSoldQuantity)
+	fmt.Fprintf(w, "Average price of all products: %s\n", average)
 }
 
-// Helper function: Find the customer who spent the least.
-func FindLowestSpender(customers []store.Customer) store.Customer {
-	if len(customers) == 0 {
-		return store.Customer{}
+// Task 10 (single-page variant): Find the product name seen on the most basket lines.
+func FindMostFrequentProductName(w io.Writer, customers []store.Customer) {
+	mostSeen, ok := MostFrequentProductName(customers)
+	if !ok {
+		fmt.Fprintln(w, "No products found in customers' baskets.")
+		return
 	}
 
-	lowestSpender := customers[0]
+	fmt.Fprintf(w, "Most frequently seen product in sales: %s (%d lines)\n", mostSeen.Name, mostSeen.Count)
+}
 
-	for _, customer := range customers {
-		if customer.Basket.Total < lowestSpender.Basket.Total {
-			lowestSpender = customer
-		}
+// Task 11 (single-page variant): Find the customer with the highest average spending per basket line.
+func FindHighestAverageLineSpending(w io.Writer, customers []store.Customer) {
+	top, ok := HighestAverageLineSpending(customers)
+	if !ok {
+		fmt.Fprintln(w, "Customer not found.")
+		return
 	}
 
-	return lowestSpender
+	fmt.Fprintf(w, "Average spending per sale: %s\n", top.Average)
+	fmt.Fprintf(w, "Customer with the highest spending: %s %s\n", top.Customer.FirstName, top.Customer.LastName)
+}
+
+// Helper function: Find the customer who spent the least.
+func FindLowestSpender(customers []store.Customer) store.Customer {
+	return AggregateCustomers(customers).LowestSpender
 }
 
 // Helper function: Find a product by its ID.
@@ -338,4 +237,4 @@ func FindProductByID(products []store.Product, productID string) store.Product {
 	}
 
 	return store.Product{}
-}
\ No newline at end of file
+}
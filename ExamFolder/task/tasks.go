@@ -0,0 +1,222 @@
+package task
+
+import (
+	"fmt"
+	"io"
+
+	"ExamFolder/store"
+)
+
+// Info describes a named analysis and how to print it. Number is the task
+// it answers; variants share the number of the task they replace.
+type Info struct {
+	Number      int
+	Name        string
+	Title       string
+	Description string
+	Print       func(w io.Writer, customers []store.Customer)
+}
+
+// Tasks lists the numbered tasks in report order.
+var Tasks = []Info{
+	{1, "customer-details", "Customer details and totals", "Prints every customer with their basket, then total cash and total spent.", PrintCustomerDetails},
+	{2, "top-spender", "Top spending customer", "Finds the customer with the highest basket total.", printTopSpender},
+	{3, "most-expensive-product", "Most expensive product", "Finds the product with the highest unit price.", printMostExpensiveProduct},
+	{4, "all-products", "All purchased products", "Lists every product line bought by any customer.", printAllProducts},
+	{5, "lowest-spender", "Lowest spending customer", "Finds the customer with the lowest basket total.", PrintLowestSpender},
+	{6, "best-selling-category", "Best-selling category", "Finds the category with the most units sold.", printBestSellingCategory},
+	{7, "min-max-sold-products", "Most and least sold products", "Finds the product lines with the highest and lowest quantity.", printMinMaxSoldProducts},
+	{8, "average-quantity-sold", "Average product lines per customer", "Divides the number of basket lines by the number of customers.", CalculateAndPrintAverageQuantitySold},
+	{9, "top-customer-by-product-quantity", "Customer with the most products", "Finds the customer with the most basket lines.", FindTopCustomerByProductQuantity},
+	{10, "most-sold-product", "Most sold product", "Finds the product ID that appears on the most basket lines.", printMostSoldProduct},
+	{11, "average-spending", "Average spending per customer", "Averages basket totals over all customers.", CalculateAndPrintAverageSpending},
+	{12, "most-profitable-category", "Most profitable category", "Finds the category with the highest price*quantity revenue.", FindMostProfitableCategory},
+	{13, "most-expensive-purchase-by-customer", "Most expensive purchase by customer", "Finds each customer's most expensive product.", FindMostExpensivePurchaseByCustomer},
+	{14, "most-expensive-category-by-customer", "Most expensive category by customer", "Finds the category each customer spent the most in.", FindMostExpensiveCategoryByCustomer},
+	{15, "total-sold-quantity", "Total quantity sold per product", "Sums units sold per product ID and overall.", printTotalSoldQuantity},
+}
+
+// Variants are the alternative readings of tasks 4, 10 and 11 that the
+// single-page program used.
+var Variants = []Info{
+	{4, "average-price", "Average price of all products", "Averages the unit price over every product line.", printAveragePrice},
+	{10, "most-frequent-product-name", "Most frequently seen product", "Finds the product name that appears on the most basket lines.", FindMostFrequentProductName},
+	{11, "highest-average-line-spending", "Highest average spending per sale", "Finds the customer whose basket lines have the highest average price*quantity.", FindHighestAverageLineSpending},
+}
+
+// Analyses lists the numbered tasks followed by their variants.
+func Analyses() []Info {
+	analyses := make([]Info, 0, len(Tasks)+len(Variants))
+	analyses = append(analyses, Tasks...)
+	return append(analyses, Variants...)
+}
+
+// AnalysisByName looks up a task or variant by name.
+func AnalysisByName(name string) (Info, bool) {
+	for _, info := range Analyses() {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// TaskInfo looks up a task by number.
+func TaskInfo(number int) (Info, bool) {
+	for _, info := range Tasks {
+		if info.Number == number {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// PrintTasks prints the numbered tasks in order. An empty numbers list
+// prints every task.
+func PrintTasks(w io.Writer, customers []store.Customer, numbers []int) error {
+	if len(numbers) == 0 {
+		for _, info := range Tasks {
+			numbers = append(numbers, info.Number)
+		}
+	}
+
+	names := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		info, ok := TaskInfo(number)
+		if !ok {
+			return fmt.Errorf("task: unknown task %d", number)
+		}
+		names = append(names, info.Name)
+	}
+
+	return PrintAnalyses(w, customers, names)
+}
+
+// PrintAnalyses prints the named analyses in order with a
+// "Task N: Title" heading each.
+func PrintAnalyses(w io.Writer, customers []store.Customer, names []string) error {
+	return printEach(w, names, func(info Info) {
+		info.Print(w, customers)
+	})
+}
+
+// aggregated prints the analyses that only need an Aggregate, so they can
+// run over a store.Streamer without loading the dataset.
+var aggregated = map[string]func(w io.Writer, a *Aggregate){
+	"top-spender":              printTopSpenderAggregate,
+	"lowest-spender":           printLowestSpender,
+	"best-selling-category":    printBestSellingCategoryAggregate,
+	"most-profitable-category": printMostProfitableCategory,
+}
+
+// Streamable reports whether every named analysis can be printed from an
+// Aggregate alone. An empty list, meaning every task, cannot.
+func Streamable(names []string) bool {
+	for _, name := range names {
+		if aggregated[name] == nil {
+			return false
+		}
+	}
+	return len(names) > 0
+}
+
+// PrintAggregate prints the named analyses like PrintAnalyses, from an
+// Aggregate built with AggregateStream. Every name must be Streamable.
+func PrintAggregate(w io.Writer, a *Aggregate, names []string) error {
+	for _, name := range names {
+		if aggregated[name] == nil {
+			return fmt.Errorf("task: analysis %q needs the whole dataset", name)
+		}
+	}
+	return printEach(w, names, func(info Info) {
+		aggregated[info.Name](w, a)
+	})
+}
+
+func printEach(w io.Writer, names []string, print func(info Info)) error {
+	for i, name := range names {
+		info, ok := AnalysisByName(name)
+		if !ok {
+			return fmt.Errorf("task: unknown analysis %q", name)
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Task %d: %s\n", info.Number, info.Title)
+		print(info)
+	}
+
+	return nil
+}
+
+func printTopSpender(w io.Writer, customers []store.Customer) {
+	printTopSpenderAggregate(w, AggregateCustomers(customers))
+}
+
+func printTopSpenderAggregate(w io.Writer, a *Aggregate) {
+	store.FprintCustomerInfo(w, a.TopSpender)
+}
+
+func printLowestSpender(w io.Writer, a *Aggregate) {
+	if a.LowestSpender.ID == "" {
+		fmt.Fprintln(w, "Customer not found.")
+		return
+	}
+
+	fmt.Fprintln(w, "Customer with the least total purchase amount:")
+	store.FprintCustomerInfo(w, a.LowestSpender)
+}
+
+func printMostExpensiveProduct(w io.Writer, customers []store.Customer) {
+	store.FprintProductInfo(w, FindMostExpensiveProduct(AllProducts(customers)))
+}
+
+func printAveragePrice(w io.Writer, customers []store.Customer) {
+	CalculateAndPrintAveragePrice(w, AllProducts(customers))
+}
+
+func printAllProducts(w io.Writer, customers []store.Customer) {
+	for _, product := range AllProducts(customers) {
+		store.FprintProductInfo(w, product)
+	}
+}
+
+func printBestSellingCategory(w io.Writer, customers []store.Customer) {
+	printBestSellingCategoryAggregate(w, AggregateCustomers(customers))
+}
+
+func printBestSellingCategoryAggregate(w io.Writer, a *Aggregate) {
+	fmt.Fprintln(w, "Best-selling category:", a.BestSellingCategory())
+}
+
+func printMostProfitableCategory(w io.Writer, a *Aggregate) {
+	if a.Customers == 0 {
+		fmt.Fprintln(w, "Customer not found.")
+		return
+	}
+
+	mostProfitable, _ := a.MostProfitableCategory()
+	fmt.Fprintf(w, "Most Profitable Category: %s (Total Profit: %s)\n", mostProfitable.Category, mostProfitable.Revenue)
+}
+
+func printMinMaxSoldProducts(w io.Writer, customers []store.Customer) {
+	maxSold, minSold := FindMinMaxSoldProducts(customers)
+	if maxSold.ID == "" || minSold.ID == "" {
+		fmt.Fprintln(w, "Product not found.")
+		return
+	}
+
+	fmt.Fprintln(w, "Most sold product:")
+	store.FprintProductInfo(w, maxSold)
+	fmt.Fprintln(w, "Least sold product:")
+	store.FprintProductInfo(w, minSold)
+}
+
+func printMostSoldProduct(w io.Writer, customers []store.Customer) {
+	FindMostSoldProduct(w, AllProducts(customers))
+}
+
+func printTotalSoldQuantity(w io.Writer, customers []store.Customer) {
+	PrintTotalSoldQuantity(w, AllProducts(customers))
+}
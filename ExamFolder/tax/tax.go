@@ -0,0 +1,217 @@
+package tax
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"ExamFolder/store"
+)
+
+// Rate is a tax rate in basis points: 100 is 1%, 2000 is 20%.
+type Rate int64
+
+// Percent builds a Rate from a whole percentage.
+func Percent(percent int64) Rate {
+	return Rate(percent * 100)
+}
+
+// percent renders the rate as a percentage with as few decimals as needed.
+// Basis points are hundredths of a percent, as minor units are of a major
+// unit, so store.Money does the formatting.
+func (r Rate) percent() string {
+	return store.Minor(int64(r), "").Decimal()
+}
+
+func (r Rate) String() string {
+	return r.percent() + "%"
+}
+
+// MarshalJSON writes the rate as a percentage, e.g. 7.5.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.percent()), nil
+}
+
+// UnmarshalJSON reads a percentage with up to two decimals exactly, the
+// way store.ParseMoney reads amounts; more decimals are an error rather
+// than being rounded.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	percent, err := store.ParseMoney(number.String())
+	if err != nil {
+		return fmt.Errorf("tax: rate %s is not a percentage with at most two decimals", number)
+	}
+	if percent.IsNegative() {
+		return fmt.Errorf("tax: negative rate %s", number)
+	}
+	*r = Rate(percent.Amount)
+	return nil
+}
+
+// Table maps Product.Category to a rate, with a fallback for categories
+// that are not listed.
+type Table struct {
+	Rates   map[string]Rate `json:"rates"`
+	Default Rate            `json:"default"`
+}
+
+// DefaultTable is the standard table: Food 1%, Beverage 10%, everything
+// else 20%.
+func DefaultTable() Table {
+	return Table{
+		Rates: map[string]Rate{
+			"Food":     Percent(1),
+			"Beverage": Percent(10),
+		},
+		Default: Percent(20),
+	}
+}
+
+// LoadTable reads a table such as {"rates": {"Food": 1}, "default": 20}.
+// Without a "default" key, unlisted categories take DefaultTable's default
+// rather than 0%; write "default": 0 to untax them.
+func LoadTable(r io.Reader) (Table, error) {
+	var wire struct {
+		Rates   map[string]Rate `json:"rates"`
+		Default *Rate           `json:"default"`
+	}
+	if err := json.NewDecoder(r).Decode(&wire); err != nil {
+		return Table{}, err
+	}
+
+	table := Table{Rates: wire.Rates, Default: DefaultTable().Default}
+	if wire.Default != nil {
+		table.Default = *wire.Default
+	}
+	return table, nil
+}
+
+// LoadTableFile reads a table from a JSON file.
+func LoadTableFile(path string) (Table, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Table{}, err
+	}
+	defer file.Close()
+
+	return LoadTable(file)
+}
+
+// RateFor returns the rate of a category.
+func (t Table) RateFor(category string) Rate {
+	if rate, ok := t.Rates[category]; ok {
+		return rate
+	}
+	return t.Default
+}
+
+// Amounts splits a tax-inclusive amount into net and tax.
+type Amounts struct {
+	Net   store.Money `json:"net"`
+	Tax   store.Money `json:"tax"`
+	Gross store.Money `json:"gross"`
+}
+
+func (a Amounts) Add(other Amounts) Amounts {
+	return Amounts{
+		Net:   a.Net.Add(other.Net),
+		Tax:   a.Tax.Add(other.Tax),
+		Gross: a.Gross.Add(other.Gross),
+	}
+}
+
+// Split treats gross as tax-inclusive, as store prices are, and extracts the
+// tax at rate. Net is rounded to the minor unit and tax takes the rest, so
+// Net + Tax always equals Gross.
+func Split(gross store.Money, rate Rate) Amounts {
+	net := gross.MulFrac(10000, 10000+int64(rate))
+	return Amounts{Net: net, Tax: gross.Sub(net), Gross: gross}
+}
+
+// LineTax is the tax breakdown of one basket line.
+type LineTax struct {
+	Product store.Product `json:"product"`
+	Rate    Rate          `json:"rate"`
+	Amounts
+}
+
+// BasketTax is the tax breakdown of a basket, line by line.
+type BasketTax struct {
+	BasketID string    `json:"basket_id"`
+	Lines    []LineTax `json:"lines"`
+	Amounts
+}
+
+// CustomerTax is the tax breakdown of what one customer spent.
+type CustomerTax struct {
+	Customer store.Customer `json:"customer"`
+	Amounts
+}
+
+// CategoryTax is the tax breakdown of one category's revenue.
+type CategoryTax struct {
+	Category string `json:"category"`
+	Rate     Rate   `json:"rate"`
+	Amounts
+}
+
+// Line computes the tax of one basket line.
+func (t Table) Line(product store.Product) LineTax {
+	rate := t.RateFor(product.Category)
+	return LineTax{Product: product, Rate: rate, Amounts: Split(product.LineTotal(), rate)}
+}
+
+// Basket computes the tax of every line and their sum. The gross is
+// recomputed from the lines, not taken from Basket.Total.
+func (t Table) Basket(basket store.Basket) BasketTax {
+	result := BasketTax{BasketID: basket.ID}
+	for _, product := range basket.Products {
+		line := t.Line(product)
+		result.Lines = append(result.Lines, line)
+		result.Amounts = result.Amounts.Add(line.Amounts)
+	}
+	return result
+}
+
+// Customer computes the tax of a customer's basket.
+func (t Table) Customer(customer store.Customer) CustomerTax {
+	return CustomerTax{Customer: customer, Amounts: t.Basket(customer.Basket).Amounts}
+}
+
+// CustomerSpending is the tax-aware customer spending report, in input order.
+func CustomerSpending(customers []store.Customer, table Table) []CustomerTax {
+	spending := make([]CustomerTax, 0, len(customers))
+	for _, customer := range customers {
+		spending = append(spending, table.Customer(customer))
+	}
+	return spending
+}
+
+// CategoryRevenues is the tax-aware category revenue report, ordered by
+// category name.
+func CategoryRevenues(customers []store.Customer, table Table) []CategoryTax {
+	var revenues []CategoryTax
+	index := make(map[string]int)
+
+	for _, customer := range customers {
+		for _, product := range customer.Basket.Products {
+			i, ok := index[product.Category]
+			if !ok {
+				i = len(revenues)
+				index[product.Category] = i
+				revenues = append(revenues, CategoryTax{Category: product.Category, Rate: table.RateFor(product.Category)})
+			}
+			revenues[i].Amounts = revenues[i].Amounts.Add(table.Line(product).Amounts)
+		}
+	}
+
+	sort.Slice(revenues, func(i, j int) bool {
+		return revenues[i].Category < revenues[j].Category
+	})
+	return revenues
+}
@@ -0,0 +1,54 @@
+package tax
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRateJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Rate
+		text  string
+	}{
+		{"20", Percent(20), "20%"},
+		{"7.5", 750, "7.5%"},
+		{"12.34", 1234, "12.34%"},
+		{"0", 0, "0%"},
+		{"1e1", Percent(10), "10%"},
+		{`"10"`, Percent(10), "10%"},
+	}
+
+	for _, test := range tests {
+		var rate Rate
+		if err := json.Unmarshal([]byte(test.input), &rate); err != nil {
+			t.Errorf("Unmarshal(%s): %v", test.input, err)
+			continue
+		}
+		if rate != test.want || rate.String() != test.text {
+			t.Errorf("Unmarshal(%s) = %d (%s), want %d (%s)", test.input, rate, rate, test.want, test.text)
+		}
+	}
+
+	for _, input := range []string{"12.345", "0.001", "-1", `"abc"`, "true"} {
+		var rate Rate
+		if err := json.Unmarshal([]byte(input), &rate); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want an error", input, rate)
+		}
+	}
+}
+
+func TestLoadTable(t *testing.T) {
+	table, err := LoadTable(strings.NewReader(`{"rates": {"Food": 1.25}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.RateFor("Food") != 125 || table.RateFor("Tech") != DefaultTable().Default {
+		t.Errorf("got %+v, want Food at 1.25%% and the standard default", table)
+	}
+
+	if _, err := LoadTable(strings.NewReader(`{"rates": {"Food": 12.345}}`)); err == nil {
+		t.Error("LoadTable accepted a rate with three decimals")
+	}
+}
@@ -0,0 +1,7 @@
+module ExamTaskOnlyMainPage
+
+go 1.21.3
+
+require ExamFolder v0.0.0
+
+replace ExamFolder => ../ExamFolder